@@ -0,0 +1,284 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"math/bits"
+	"time"
+)
+
+// AppendFormat appends b written as with the Printf verb, such as 'v' or 'K',
+// to dst and returns the extended buffer.  Values with a magnitude which fits
+// in 64 bits are written without allocating.
+func (b Bytes) AppendFormat(dst []byte, verb rune) []byte {
+	if out, ok := appendFast(dst, b.num, verb, 'B', "B", false); ok {
+		return out
+	}
+	st := appendState(dst)
+	formatByte(b.num, nil, &st, verb, 'B', "B", nil)
+	return st
+}
+
+// AppendFormat appends b written as with the Printf verb, such as 'v' or 'K',
+// to dst and returns the extended buffer.  Values with a magnitude which fits
+// in 64 bits are written without allocating.
+func (b Bits) AppendFormat(dst []byte, verb rune) []byte {
+	if out, ok := appendFast(dst, b.num, verb, 'b', "b", false); ok {
+		return out
+	}
+	st := appendState(dst)
+	formatByte(b.num, nil, &st, verb, 'b', "b", nil)
+	return st
+}
+
+// AppendFormat appends b written as with the Printf verb, such as 'v' or 'K',
+// to dst and returns the extended buffer.  Rates over one second which fit in
+// 64 bits are written without allocating.
+func (b ByteRate) AppendFormat(dst []byte, verb rune) []byte {
+	if b.d == time.Second {
+		if out, ok := appendFast(dst, b.n.num, verb, 'B', "B/s", true); ok {
+			return out
+		}
+	}
+	st := appendState(dst)
+	formatByte(b.n.num, rateScale(b.d), &st, verb, 'B', "B/s", nil)
+	return st
+}
+
+// AppendFormat appends b written as with the Printf verb, such as 'v' or 'K',
+// to dst and returns the extended buffer.  Rates over one second which fit in
+// 64 bits are written without allocating.
+func (b BitRate) AppendFormat(dst []byte, verb rune) []byte {
+	if b.d == time.Second {
+		if out, ok := appendFast(dst, b.n.num, verb, 'b', "bps", true); ok {
+			return out
+		}
+	}
+	st := appendState(dst)
+	formatByte(b.n.num, rateScale(b.d), &st, verb, 'b', "bps", nil)
+	return st
+}
+
+// appendState is a fmt.State without flags which appends to a buffer
+type appendState []byte
+
+func (s *appendState) Write(b []byte) (int, error) {
+	*s = append(*s, b...)
+	return len(b), nil
+}
+func (s *appendState) Width() (int, bool)     { return 0, false }
+func (s *appendState) Precision() (int, bool) { return 0, false }
+func (s *appendState) Flag(c int) bool        { return false }
+
+// toUint64 gives the value of the big endian b when it fits in 64 bits
+func toUint64(b []byte) (uint64, bool) {
+	var n uint64
+	for _, c := range b {
+		if n>>56 != 0 {
+			return 0, false
+		}
+		n = n<<8 | uint64(c)
+	}
+	return n, true
+}
+
+// fromUint64 gives the shortest big endian bytes of n
+func fromUint64(n uint64) []byte {
+	k := (bits.Len64(n) + 7) / 8
+	b := make([]byte, k)
+	for i := k - 1; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+	return b
+}
+
+var pow10 = [...]uint64{1, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10,
+	1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19}
+
+// appendFast writes b as formatByte does with no flags, width or precision,
+// using integer arithmetic.  The output is only given, with ok set, when it is
+// certain to match the big.Float formatting, which carries 8 bits of
+// precision for each byte of b, so leading zero bytes are left to big.Float.
+// Rates are exact and only the values with up to rateDigits digits are given.
+func appendFast(dst []byte, x num, verb, def rune, suf string, rate bool) (out []byte, ok bool) {
+	neg, b := isNeg(x), x.mag
+	n, ok := toUint64(b)
+	if !ok || len(b) > 0 && b[0] == 0 {
+		return dst, false
+	}
+
+	// Pick the power of 1000 or 1024 to divide by and the label to write
+	k, binary, iec, word := 0, false, false, -1
+	var label string
+	switch verb {
+	case def:
+	case 'v', 's':
+		if n >= 250 {
+			m := n >> 8
+			for k = 1; pow10[3*k] <= m; k++ {
+			}
+			label, word = thousandVerb[20+k-1:20+k], k-1
+		}
+	case 'V', 'S':
+		if n >= 256 {
+			m := n >> 8
+			for k = 1; uint64(1)<<(10*k) <= m; k++ {
+			}
+			binary, label, word = true, thousandVerb[k-1:k], 10+k-1
+			iec = true
+		}
+	default:
+		i := 0
+		for ; i < 20; i++ {
+			if rune(thousandVerb[i]) == verb {
+				break
+			}
+		}
+		switch {
+		case i == 20:
+			return dst, false
+		case i < 10:
+			k, binary, label = i+1, true, thousandVerb[i+20:i+21]
+		default:
+			k, label, iec = i-9, thousandVerb[i%10:i%10+1], true
+		}
+	}
+
+	// Find the exact decimal digits m with f digits after the decimal point
+	m, f := n, 0
+	if binary {
+		// n / 2^10k, carry the factors of two in n over before scaling by 5
+		t := bits.TrailingZeros64(n)
+		if n == 0 || t >= 10*k {
+			m = n >> (10 * k)
+		} else {
+			f = 10*k - t
+			if f > 27 {
+				return dst, false
+			}
+			hi, lo := bits.Mul64(n>>t, pow5(f))
+			if hi != 0 {
+				return dst, false
+			}
+			m = lo
+		}
+	} else {
+		f = 3 * k
+	}
+	if m == 0 {
+		f = 0
+	}
+	for m != 0 && m%10 == 0 {
+		m /= 10
+		f--
+	}
+
+	// The big.Float holds 8 bits a byte, the shortest digits are the exact
+	// digits when a step in the last digit is larger than the float's ulp
+	sd := 1
+	for sd < len(pow10) && pow10[sd] <= m {
+		sd++
+	}
+	if rate {
+		if sd > rateDigits {
+			return dst, false
+		}
+	} else if p := 8 * len(b); p < 64 {
+		if sd >= 19 || 2*pow10[sd] >= uint64(1)<<p {
+			return dst, false
+		}
+	} else if sd > 18 {
+		return dst, false
+	}
+
+	var digits [20]byte
+	for i := sd - 1; i >= 0; i-- {
+		digits[i] = byte('0' + m%10)
+		m /= 10
+	}
+	ds := digits[:sd]
+	if neg {
+		dst = append(dst, '-')
+	}
+	if n == 0 {
+		dst = append(dst, '0')
+	} else if dp := sd - f; dp-1 < -4 || dp-1 >= 6 {
+		// Written as %e
+		dst = append(dst, ds[0])
+		if sd > 1 {
+			dst = append(dst, '.')
+			dst = append(dst, ds[1:]...)
+		}
+		dst = append(dst, 'e')
+		exp := dp - 1
+		if exp < 0 {
+			dst = append(dst, '-')
+			exp = -exp
+		} else {
+			dst = append(dst, '+')
+		}
+		if exp < 10 {
+			dst = append(dst, '0')
+		}
+		for p := 100; p > 0; p /= 10 {
+			if exp >= p || p < 10 {
+				dst = append(dst, byte('0'+exp/p%10))
+			}
+		}
+	} else if dp <= 0 {
+		dst = append(dst, '0', '.')
+		for ; dp < 0; dp++ {
+			dst = append(dst, '0')
+		}
+		dst = append(dst, ds...)
+	} else if dp >= sd {
+		dst = append(dst, ds...)
+		for ; dp > sd; dp-- {
+			dst = append(dst, '0')
+		}
+	} else {
+		dst = append(dst, ds[:dp]...)
+		dst = append(dst, '.')
+		dst = append(dst, ds[dp:]...)
+	}
+
+	if verb == 's' || verb == 'S' {
+		if word >= 0 {
+			dst = append(dst, thousandWord[word]...)
+		}
+		switch suf {
+		case "b":
+			return append(dst, "Bit"...), true
+		case "B":
+			return append(dst, "Byte"...), true
+		}
+		return append(dst, suf...), true
+	}
+	dst = append(dst, label...)
+	if iec {
+		dst = append(dst, 'i')
+	}
+	return append(dst, suf...), true
+}
+
+// pow5 gives 5^n for n up to 27
+func pow5(n int) uint64 {
+	p := uint64(1)
+	for ; n > 0; n-- {
+		p *= 5
+	}
+	return p
+}
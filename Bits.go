@@ -0,0 +1,69 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cymertek/go-big"
+)
+
+// RoundingMode selects how a value which does not land on a whole unit is
+// handled.  The zero value is RoundNearest.
+type RoundingMode int
+
+const (
+	RoundNearest RoundingMode = iota // Round to the nearest whole unit, half way rounds up
+	RoundFloor                       // Drop the remainder
+	RoundCeil                        // Round up to the next whole unit
+	RoundExact                       // Return an error when there is a remainder
+)
+
+// ToBits converts the number of bytes into the exact number of bits
+func (b Bytes) ToBits() Bits {
+	i := intBytes(b.num)
+	return Bits{bytesInt(i.Lsh(i, 3))}
+}
+
+// ToBytes converts the number of bits into bytes, a trailing partial byte is
+// handled by the given rounding mode, which works on the magnitude of a
+// negative value.  An error is only returned with RoundExact.
+func (b Bits) ToBytes(mode RoundingMode) (Bytes, error) {
+	i := (&big.Int{}).SetBytes(b.mag)
+	rem := i.Bit(0) | i.Bit(1)<<1 | i.Bit(2)<<2
+	i.Rsh(i, 3)
+	switch mode {
+	case RoundFloor:
+	case RoundCeil:
+		if rem > 0 {
+			i.Add(i, big.NewInt(1))
+		}
+	case RoundNearest:
+		if rem >= 4 {
+			i.Add(i, big.NewInt(1))
+		}
+	case RoundExact:
+		if rem > 0 {
+			return Bytes{}, fmt.Errorf("%w of %d bits converting to bytes", ErrRemainder, rem)
+		}
+	default:
+		return Bytes{}, errors.New("binary unit: unknown rounding mode")
+	}
+	if isNeg(b.num) {
+		i.Neg(i)
+	}
+	return Bytes{bytesInt(i)}, nil
+}
@@ -15,12 +15,29 @@
 package bunit
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"strings"
 	"time"
 )
 
-type Bytes []byte
-type Bits []byte
+// num is a signed amount held as an unsigned big endian magnitude and a
+// separate sign, so any byte slice given as a magnitude keeps its meaning.  The
+// zero value is zero.
+type num struct {
+	mag []byte
+	neg bool
+}
+
+// Bytes is a count of bytes of any size and sign
+type Bytes struct{ num }
+
+// Bits is a count of bits of any size and sign
+type Bits struct{ num }
 type ByteRate struct {
 	n Bytes
 	d time.Duration
@@ -39,6 +56,38 @@ var _ fmt.Formatter = bitZero      // Bits must implement fmt.Formatter
 var _ fmt.Formatter = byteRateZero // ByteRate must implement fmt.Formatter
 var _ fmt.Formatter = bitRateZero  // BitRate must implement fmt.Formatter
 
+var _ encoding.TextMarshaler = byteZero        // Bytes must implement encoding.TextMarshaler
+var _ encoding.TextMarshaler = bitZero         // Bits must implement encoding.TextMarshaler
+var _ encoding.TextMarshaler = byteRateZero    // ByteRate must implement encoding.TextMarshaler
+var _ encoding.TextMarshaler = bitRateZero     // BitRate must implement encoding.TextMarshaler
+var _ encoding.TextUnmarshaler = &byteZero     // Bytes must implement encoding.TextUnmarshaler
+var _ encoding.TextUnmarshaler = &bitZero      // Bits must implement encoding.TextUnmarshaler
+var _ encoding.TextUnmarshaler = &byteRateZero // ByteRate must implement encoding.TextUnmarshaler
+var _ encoding.TextUnmarshaler = &bitRateZero  // BitRate must implement encoding.TextUnmarshaler
+
+var _ json.Marshaler = byteZero        // Bytes must implement json.Marshaler
+var _ json.Marshaler = bitZero         // Bits must implement json.Marshaler
+var _ json.Marshaler = byteRateZero    // ByteRate must implement json.Marshaler
+var _ json.Marshaler = bitRateZero     // BitRate must implement json.Marshaler
+var _ json.Unmarshaler = &byteZero     // Bytes must implement json.Unmarshaler
+var _ json.Unmarshaler = &bitZero      // Bits must implement json.Unmarshaler
+var _ json.Unmarshaler = &byteRateZero // ByteRate must implement json.Unmarshaler
+var _ json.Unmarshaler = &bitRateZero  // BitRate must implement json.Unmarshaler
+
+var _ flag.Getter = &byteZero     // Bytes must implement flag.Getter
+var _ flag.Getter = &bitZero      // Bits must implement flag.Getter
+var _ flag.Getter = &byteRateZero // ByteRate must implement flag.Getter
+var _ flag.Getter = &bitRateZero  // BitRate must implement flag.Getter
+
+var _ driver.Valuer = byteZero     // Bytes must implement driver.Valuer
+var _ driver.Valuer = bitZero      // Bits must implement driver.Valuer
+var _ driver.Valuer = byteRateZero // ByteRate must implement driver.Valuer
+var _ driver.Valuer = bitRateZero  // BitRate must implement driver.Valuer
+var _ sql.Scanner = &byteZero      // Bytes must implement sql.Scanner
+var _ sql.Scanner = &bitZero       // Bits must implement sql.Scanner
+var _ sql.Scanner = &byteRateZero  // ByteRate must implement sql.Scanner
+var _ sql.Scanner = &bitRateZero   // BitRate must implement sql.Scanner
+
 const (
 	// Decimal binary values
 	Byte       = float64(1)
@@ -134,6 +183,50 @@ var unitMap = map[string]float64{
 	"qubi": 1 << 100,
 }
 
+// Prefixes by lower case for matching without regard to case
+var foldMap = func() map[string]float64 {
+	m := make(map[string]float64)
+	for k, v := range unitMap {
+		m[strings.ToLower(k)] = v
+	}
+	return m
+}()
+
+// Decimal prefixes read as powers of 1024 under ConventionJEDEC
+var jedecMap = map[string]float64{
+	"k":      1 << 10,
+	"K":      1 << 10,
+	"Kilo":   1 << 10,
+	"kilo":   1 << 10,
+	"M":      1 << 20,
+	"Mega":   1 << 20,
+	"mega":   1 << 20,
+	"G":      1 << 30,
+	"Giga":   1 << 30,
+	"giga":   1 << 30,
+	"T":      1 << 40,
+	"Tera":   1 << 40,
+	"tera":   1 << 40,
+	"P":      1 << 50,
+	"Peta":   1 << 50,
+	"peta":   1 << 50,
+	"E":      1 << 60,
+	"Exa":    1 << 60,
+	"exa":    1 << 60,
+	"Z":      1 << 70,
+	"Zetta":  1 << 70,
+	"zetta":  1 << 70,
+	"Y":      1 << 80,
+	"Yotta":  1 << 80,
+	"yotta":  1 << 80,
+	"R":      1 << 90,
+	"Ronna":  1 << 90,
+	"ronna":  1 << 90,
+	"Q":      1 << 100,
+	"Quetta": 1 << 100,
+	"quetta": 1 << 100,
+}
+
 var thousandVerb = "KMGTPEZYRQkmgtpezyrqkMGTPEZYRQ"
 var thousandWord = []string{
 	"Kilo",
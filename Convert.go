@@ -1,57 +1,73 @@
 package bunit
 
-import (
-	"time"
-
-	"github.com/cymertek/go-big"
-)
+import "github.com/cymertek/go-big"
 
 // Get the int64 value of the bytes, may be cut off for large values
 func (b Bytes) Int64() int64 {
-	return (&big.Int{}).SetBytes(b).Int64()
+	if n, ok := toUint64(b.mag); ok && !b.neg && len(b.mag) > 0 && b.mag[0] != 0 {
+		return int64(n)
+	}
+	return intBytes(b.num).Int64()
 }
 
 // Get the int64 value of the bits, may be cut off for large values
 func (b Bits) Int64() int64 {
-	return (&big.Int{}).SetBytes(b).Int64()
+	if n, ok := toUint64(b.mag); ok && !b.neg && len(b.mag) > 0 && b.mag[0] != 0 {
+		return int64(n)
+	}
+	return intBytes(b.num).Int64()
 }
 
 // Get the big.Int value of the bytes, may be cut off for large values
 func (b Bytes) Int() *big.Int {
-	return (&big.Int{}).SetBytes(b)
+	return intBytes(b.num)
 }
 
 // Get the big.Int value of the bits, may be cut off for large values
 func (b Bits) Int() *big.Int {
-	return (&big.Int{}).SetBytes(b)
+	return intBytes(b.num)
+}
+
+// Get a copy of the unsigned big endian magnitude of the bytes, the sign is
+// given by Sign.  This is the byte slice Bytes used to be.
+func (b Bytes) Magnitude() []byte {
+	return append([]byte(nil), b.mag...)
+}
+
+// Get a copy of the unsigned big endian magnitude of the bits, the sign is
+// given by Sign.  This is the byte slice Bits used to be.
+func (b Bits) Magnitude() []byte {
+	return append([]byte(nil), b.mag...)
 }
 
 // Get the int64 value of the bit rate per second, may be cut off for large values
 func (b BitRate) Int64() int64 {
-	v := (&big.Float{}).SetBytes(b.n, []byte{})
-	v.Mul(v, big.NewFloat(float64(time.Second)/float64(b.d)))
-	r, _ := v.Int64()
-	return r
+	return ratInt(b.Rat()).Int64()
 }
 
 // Get the int64 value of the byte rate per second, may be cut off for large values
 func (b ByteRate) Int64() int64 {
-	v := (&big.Float{}).SetBytes(b.n, []byte{})
-	v.Mul(v, big.NewFloat(float64(time.Second)/float64(b.d)))
-	r, _ := v.Int64()
-	return r
+	return ratInt(b.Rat()).Int64()
 }
 
-// Get the big.Float value of the bit rate per second, may be cut off for large values
+// Get the big.Float value of the bit rate per second, rounded once from the
+// exact rate
 func (b BitRate) Float() *big.Float {
-	v := (&big.Float{}).SetBytes(b.n, []byte{})
-	v.Mul(v, big.NewFloat(float64(time.Second)/float64(b.d)))
-	return v
+	return (&big.Float{}).SetRat(b.Rat())
 }
 
-// Get the big.Float value of the byte rate per second, may be cut off for large values
+// Get the big.Float value of the byte rate per second, rounded once from the
+// exact rate
 func (b ByteRate) Float() *big.Float {
-	v := (&big.Float{}).SetBytes(b.n, []byte{})
-	v.Mul(v, big.NewFloat(float64(time.Second)/float64(b.d)))
-	return v
+	return (&big.Float{}).SetRat(b.Rat())
+}
+
+// Get the exact big.Rat value of the bit rate per second
+func (b BitRate) Rat() *big.Rat {
+	return ratePerSecond(b.n.num, b.d)
+}
+
+// Get the exact big.Rat value of the byte rate per second
+func (b ByteRate) Rat() *big.Rat {
+	return ratePerSecond(b.n.num, b.d)
 }
@@ -0,0 +1,122 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cymertek/go-big"
+)
+
+// Reasons a rate could not be found from two counter readings, for use with
+// errors.Is
+var (
+	ErrCounterReset = errors.New("binary unit: counter reset")
+	ErrCounterRange = errors.New("binary unit: counter out of range")
+	ErrTimeOrder    = errors.New("binary unit: timestamps not increasing")
+	ErrNoPrevious   = errors.New("binary unit: no previous counter reading")
+)
+
+// RateFromCounters gives the rate at which an octet counter, such as an SNMP
+// ifHCInOctets or a /proc/net/dev column, moved from prev at prevT to cur at
+// curT.  A counter of width bits, such as 32 or 64, wraps back to zero and a
+// width of 0 is a counter which never wraps.  A drop in the counter is taken
+// as a wrap when the wrapped difference is under half the range of the
+// counter, otherwise ErrCounterReset is returned.
+func RateFromCounters(prev, cur Bytes, prevT, curT time.Time, width int) (ByteRate, error) {
+	n, d, err := counterDelta(prev.num, cur.num, prevT, curT, width)
+	return ByteRate{Bytes{n}, d}, err
+}
+
+// BitRateFromCounters is like RateFromCounters for a counter of bits
+func BitRateFromCounters(prev, cur Bits, prevT, curT time.Time, width int) (BitRate, error) {
+	n, d, err := counterDelta(prev.num, cur.num, prevT, curT, width)
+	return BitRate{Bits{n}, d}, err
+}
+
+// CounterRate tracks the readings of an octet counter and gives the rate
+// between each reading and the one before.  A CounterRate is safe for
+// concurrent use.
+type CounterRate struct {
+	mu    sync.Mutex
+	width int
+	prev  Bytes
+	prevT time.Time
+	ok    bool
+}
+
+// NewCounterRate returns a CounterRate for a counter of width bits, or 0 for
+// a counter which never wraps
+func NewCounterRate(width int) *CounterRate {
+	return &CounterRate{width: width}
+}
+
+// Update records the reading cur taken at t and gives the rate since the last
+// reading.  The first reading, and the first after a counter reset, gives
+// ErrNoPrevious or ErrCounterReset and starts the tracking over.  A reading
+// which is not after the last one, or out of the range of the counter, is
+// dropped with ErrTimeOrder or ErrCounterRange.
+func (c *CounterRate) Update(cur Bytes, t time.Time) (ByteRate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.ok {
+		c.prev, c.prevT, c.ok = Bytes{copyBytes(cur.num)}, t, true
+		return ByteRate{}, ErrNoPrevious
+	}
+	r, err := RateFromCounters(c.prev, cur, c.prevT, t, c.width)
+	if err == nil || err == ErrCounterReset {
+		c.prev, c.prevT = Bytes{copyBytes(cur.num)}, t
+	}
+	return r, err
+}
+
+// Reset forgets the last reading
+func (c *CounterRate) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prev, c.prevT, c.ok = Bytes{}, time.Time{}, false
+}
+
+// counterDelta finds the exact change of the counter and the time it took
+func counterDelta(prev, cur num, prevT, curT time.Time, width int) (num, time.Duration, error) {
+	d := curT.Sub(prevT)
+	if d <= 0 {
+		return num{}, 0, ErrTimeOrder
+	}
+	p, c := intBytes(prev), intBytes(cur)
+	var limit *big.Int
+	if width > 0 {
+		limit = (&big.Int{}).Lsh(big.NewInt(1), uint(width))
+	}
+	for _, v := range []*big.Int{p, c} {
+		if v.Sign() < 0 || limit != nil && v.Cmp(limit) >= 0 {
+			return num{}, 0, ErrCounterRange
+		}
+	}
+
+	n := c.Sub(c, p)
+	if n.Sign() < 0 {
+		if limit == nil {
+			return num{}, 0, ErrCounterReset
+		}
+		// A wrap moves the counter less than half way around
+		if n.Add(n, limit).Cmp(limit.Rsh(limit, 1)) >= 0 {
+			return num{}, 0, ErrCounterReset
+		}
+	}
+	return bytesInt(n), d, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// CountingReader wraps an io.Reader and keeps a total of the bytes read.  The
+// total may be read from other goroutines while reading is in progress.
+type CountingReader struct {
+	n     int64 // first for 64 bit alignment of atomic access
+	r     io.Reader
+	start time.Time
+	now   func() time.Time
+}
+
+// CountingWriter wraps an io.Writer and keeps a total of the bytes written.
+// The total may be read from other goroutines while writing is in progress.
+type CountingWriter struct {
+	n     int64 // first for 64 bit alignment of atomic access
+	w     io.Writer
+	start time.Time
+	now   func() time.Time
+}
+
+// NewCountingReader returns a CountingReader reading from r
+func NewCountingReader(r io.Reader) *CountingReader {
+	return NewCountingReaderWithClock(r, time.Now)
+}
+
+// NewCountingReaderWithClock is like NewCountingReader but reads the time from
+// now, allowing for deterministic tests
+func NewCountingReaderWithClock(r io.Reader, now func() time.Time) *CountingReader {
+	return &CountingReader{r: r, start: now(), now: now}
+}
+
+// NewCountingWriter returns a CountingWriter writing to w
+func NewCountingWriter(w io.Writer) *CountingWriter {
+	return NewCountingWriterWithClock(w, time.Now)
+}
+
+// NewCountingWriterWithClock is like NewCountingWriter but reads the time from
+// now, allowing for deterministic tests
+func NewCountingWriterWithClock(w io.Writer, now func() time.Time) *CountingWriter {
+	return &CountingWriter{w: w, start: now(), now: now}
+}
+
+// Read implements the io.Reader interface
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
+
+// Total gives the number of bytes read so far
+func (c *CountingReader) Total() Bytes {
+	return *NewBytes(atomic.LoadInt64(&c.n))
+}
+
+// Rate gives the average rate of reading since the CountingReader was created
+func (c *CountingReader) Rate() ByteRate {
+	return ByteRate{c.Total(), c.now().Sub(c.start)}
+}
+
+// Write implements the io.Writer interface
+func (c *CountingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
+
+// Total gives the number of bytes written so far
+func (c *CountingWriter) Total() Bytes {
+	return *NewBytes(atomic.LoadInt64(&c.n))
+}
+
+// Rate gives the average rate of writing since the CountingWriter was created
+func (c *CountingWriter) Rate() ByteRate {
+	return ByteRate{c.Total(), c.now().Sub(c.start)}
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"errors"
+)
+
+// Reasons for a ParseError, for use with errors.Is
+var (
+	ErrBadNumber   = errors.New("binary unit: bad number")
+	ErrUnknownUnit = errors.New("binary unit: unknown unit")
+	ErrMissingUnit = errors.New("binary unit: missing unit")
+	ErrNegative    = errors.New("binary unit: negative value")
+	ErrBadDuration = errors.New("binary unit: bad duration")
+	ErrOverflow    = errors.New("binary unit: value too large")
+	ErrRemainder   = errors.New("binary unit: partial byte")
+	ErrSyntax      = errors.New("binary unit: invalid syntax")
+)
+
+// ParseError describes a value which could not be parsed
+type ParseError struct {
+	Input  string // The value given to the parser
+	Offset int    // Byte offset into Input where parsing failed
+	Token  string // The offending text
+	Err    error  // The reason, one of the Err values of this package
+	msg    string
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	if e.msg == "" {
+		return e.Err.Error() + " in value " + quote(e.Input)
+	}
+	return "binary unit: " + e.msg
+}
+
+// Unwrap gives the reason for the error
+func (e *ParseError) Unwrap() error { return e.Err }
+
+func parseError(input string, offset int, token string, reason error, msg string) *ParseError {
+	return &ParseError{Input: input, Offset: offset, Token: token, Err: reason, msg: msg}
+}
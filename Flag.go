@@ -0,0 +1,110 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"flag"
+)
+
+// Set implements the flag.Value interface using ParseBytes
+func (b *Bytes) Set(s string) error {
+	v, err := ParseBytes(s)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// Get implements the flag.Getter interface
+func (b *Bytes) Get() interface{} { return *b }
+
+// Set implements the flag.Value interface using ParseBits
+func (b *Bits) Set(s string) error {
+	v, err := ParseBits(s)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// Get implements the flag.Getter interface
+func (b *Bits) Get() interface{} { return *b }
+
+// Set implements the flag.Value interface using ParseByteRate
+func (b *ByteRate) Set(s string) error {
+	v, err := ParseByteRate(s)
+	if err != nil {
+		return err
+	}
+	*b = *v
+	return nil
+}
+
+// Get implements the flag.Getter interface
+func (b *ByteRate) Get() interface{} { return *b }
+
+// Set implements the flag.Value interface using ParseBitRate
+func (b *BitRate) Set(s string) error {
+	v, err := ParseBitRate(s)
+	if err != nil {
+		return err
+	}
+	*b = *v
+	return nil
+}
+
+// Get implements the flag.Getter interface
+func (b *BitRate) Get() interface{} { return *b }
+
+// BytesVar defines a Bytes flag with specified name, default value, and usage
+// string.  The argument p points to a Bytes variable in which to store the
+// value of the flag.  A nil fs uses flag.CommandLine.
+func BytesVar(fs *flag.FlagSet, p *Bytes, name string, def Bytes, usage string) {
+	*p = def
+	flagSet(fs).Var(p, name, usage)
+}
+
+// BitsVar defines a Bits flag with specified name, default value, and usage
+// string.  The argument p points to a Bits variable in which to store the
+// value of the flag.  A nil fs uses flag.CommandLine.
+func BitsVar(fs *flag.FlagSet, p *Bits, name string, def Bits, usage string) {
+	*p = def
+	flagSet(fs).Var(p, name, usage)
+}
+
+// ByteRateVar defines a ByteRate flag with specified name, default value, and
+// usage string.  The argument p points to a ByteRate variable in which to
+// store the value of the flag.  A nil fs uses flag.CommandLine.
+func ByteRateVar(fs *flag.FlagSet, p *ByteRate, name string, def ByteRate, usage string) {
+	*p = def
+	flagSet(fs).Var(p, name, usage)
+}
+
+// BitRateVar defines a BitRate flag with specified name, default value, and
+// usage string.  The argument p points to a BitRate variable in which to
+// store the value of the flag.  A nil fs uses flag.CommandLine.
+func BitRateVar(fs *flag.FlagSet, p *BitRate, name string, def BitRate, usage string) {
+	*p = def
+	flagSet(fs).Var(p, name, usage)
+}
+
+func flagSet(fs *flag.FlagSet) *flag.FlagSet {
+	if fs == nil {
+		return flag.CommandLine
+	}
+	return fs
+}
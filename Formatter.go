@@ -2,6 +2,8 @@ package bunit
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cymertek/go-big"
@@ -9,117 +11,138 @@ import (
 
 // Format for use in Printf
 func (b Bits) Format(f fmt.State, verb rune) {
-	formatByte(b, 1, f, verb, 'b', "b")
+	formatByte(b.num, nil, f, verb, 'b', "b", nil)
 }
 
 // Format for use with stringify
 func (b Bits) String() string {
-	return fmt.Sprintf("%v", b)
+	var buf [64]byte
+	return string(b.AppendFormat(buf[:0], 'v'))
 }
 
 // Format for use in Printf
 func (b BitRate) Format(f fmt.State, verb rune) {
-	formatByte(b.n, float64(time.Second)/float64(b.d), f, verb, 'b', "bps")
+	formatByte(b.n.num, rateScale(b.d), f, verb, 'b', "bps", nil)
 }
 
 // Format for use with stringify
 func (b BitRate) String() string {
-	return fmt.Sprintf("%v", b)
+	var buf [64]byte
+	return string(b.AppendFormat(buf[:0], 'v'))
 }
 
 // Format for use in Printf
 func (b Bytes) Format(f fmt.State, verb rune) {
-	formatByte(b, 1, f, verb, 'B', "B")
+	formatByte(b.num, nil, f, verb, 'B', "B", nil)
 }
 
 // Format for use with stringify
 func (b Bytes) String() string {
-	return fmt.Sprintf("%V", b)
+	var buf [64]byte
+	return string(b.AppendFormat(buf[:0], 'V'))
 }
 
 // Format for use in Printf
 func (b ByteRate) Format(f fmt.State, verb rune) {
-	formatByte(b.n, float64(time.Second)/float64(b.d), f, verb, 'B', "B/s")
+	formatByte(b.n.num, rateScale(b.d), f, verb, 'B', "B/s", nil)
 }
 
 // Format for use with stringify
 func (b ByteRate) String() string {
-	return fmt.Sprintf("%V", b)
+	var buf [64]byte
+	return string(b.AppendFormat(buf[:0], 'V'))
 }
 
-func formatByte(b []byte, scale float64, f fmt.State, verb, def rune, suf string) {
-	v := (&big.Float{}).SetBytes(b, []byte{})
-	if scale != 1 {
-		v = v.Mul(v, big.NewFloat(scale))
+// plainState reports if f has no flags, width or precision
+func plainState(f fmt.State) bool {
+	if _, ok := f.Width(); ok {
+		return false
 	}
+	if _, ok := f.Precision(); ok {
+		return false
+	}
+	return !f.Flag('#') && !f.Flag('+') && !f.Flag('-') && !f.Flag(' ') && !f.Flag('0')
+}
+
+// rateScale gives the exact multiplier to bring a rate over d to per second,
+// an unset duration is treated as a zero rate
+func rateScale(d time.Duration) *big.Rat {
+	if d == 0 {
+		return &big.Rat{}
+	}
+	return big.NewRat(int64(time.Second), int64(d))
+}
+
+// rateDigits is the number of significant digits a rate which does not end
+// is written with when no precision is given
+const rateDigits = 10
+
+// formatByte writes x with the verb.  Sizes are given with a nil scale and
+// carry 8 bits of precision a byte, rates are scaled by the exact scale and
+// only rounded when the digits are written.
+func formatByte(x num, scale *big.Rat, f fmt.State, verb, def rune, suf string, loc *Locale) {
+	if loc == nil && (scale == nil || scale.IsInt() && scale.Num().IsInt64() && scale.Num().Int64() == 1) && plainState(f) {
+		var buf [64]byte
+		if out, ok := appendFast(buf[:0], x, verb, def, suf, scale != nil); ok {
+			f.Write(out)
+			return
+		}
+	}
+	// Pick the prefix on the magnitude and put the sign back on at the end
+	neg, b := isNeg(x), x.mag
+	jedec := f.Flag('#') // Label powers of 1024 as KB, MB, GB
+	var v *big.Float
+	var r *big.Rat
+	var n *big.Int
+	if scale == nil {
+		v = (&big.Float{}).SetBytes(b, []byte{})
+		n, _ = v.Int(nil)
+	} else {
+		r = (&big.Rat{}).SetInt((&big.Int{}).SetBytes(b))
+		if r.Mul(r, scale).Sign() < 0 {
+			neg = !neg
+			r.Neg(r)
+		}
+		n = ratInt(r)
+	}
+
+	var div []byte
+	word := -1
 	switch verb {
 	case def:
-	case 'v':
+	case 'v', 's':
 		// Auto with 1000 multiples
-		n, _ := v.Int(nil)
 		n = n.Lsh(n, 2)
 		if (&big.Int{}).SetBytes(thousand[10]).Cmp(n) <= 0 {
 			n = n.Rsh(n, 10)
 			for i := range thousandVerb[:10] {
 				if (&big.Int{}).SetBytes(thousand[10+i]).Cmp(n) > 0 {
-					v.Quo(v, (&big.Float{}).SetBytes(thousand[10+i], []byte{}))
-					suf = string(thousandVerb[i+20]) + suf
+					div, word = thousand[10+i], i
+					if verb == 'v' {
+						suf = string(thousandVerb[i+20]) + suf
+					}
 					break
 				}
 			}
 		}
-	case 's':
-		// String with 1000 multiples
-		n, _ := v.Int(nil)
-		n = n.Lsh(n, 2)
-		switch suf {
-		case "b":
-			suf = "Bit"
-		case "B":
-			suf = "Byte"
-		}
-		if (&big.Int{}).SetBytes(thousand[10]).Cmp(n) <= 0 {
-			n = n.Rsh(n, 10)
-			for i := range thousandVerb[:10] {
-				if (&big.Int{}).SetBytes(thousand[10+i]).Cmp(n) > 0 {
-					v.Quo(v, (&big.Float{}).SetBytes(thousand[10+i], []byte{}))
-					suf = thousandWord[i] + suf
-					break
-				}
-			}
-		}
-
-	case 'V':
+	case 'V', 'S':
 		// Auto with 1024 multiples
-		n, _ := v.Int(nil)
 		n = n.Lsh(n, 2)
 		if (&big.Int{}).SetBytes(thousand[0]).Cmp(n) <= 0 {
 			n = n.Rsh(n, 10)
 			for i, c := range thousandVerb[:10] {
 				if (&big.Int{}).SetBytes(thousand[i]).Cmp(n) > 0 {
-					v.Quo(v, (&big.Float{}).SetBytes(thousand[i], []byte{}))
-					suf = string(c) + "i" + suf
-					break
-				}
-			}
-		}
-
-	case 'S':
-		// String with 1024 multiples
-		n, _ := v.Int(nil)
-		n = n.Lsh(n, 2)
-		switch suf {
-		case "b":
-			suf = "Bit"
-		case "B":
-			suf = "Byte"
-		}
-		if (&big.Int{}).SetBytes(thousand[0]).Cmp(n) <= 0 {
-			n = n.Rsh(n, 10)
-			for i := range thousandVerb[:10] {
-				if (&big.Int{}).SetBytes(thousand[i]).Cmp(n) > 0 {
-					v.Quo(v, (&big.Float{}).SetBytes(thousand[i], []byte{}))
-					suf = thousandWord[10+i] + suf
+					div, word = thousand[i], 10+i
+					if jedec {
+						word = i
+					}
+					if verb == 'V' {
+						if jedec {
+							suf = string(c) + suf
+						} else {
+							suf = string(c) + "i" + suf
+						}
+					}
 					break
 				}
 			}
@@ -127,11 +150,11 @@ func formatByte(b []byte, scale float64, f fmt.State, verb, def rune, suf string
 	default:
 		// All the SI Byte units
 		for i, c := range thousandVerb[:20] {
-			//fmt.Printf("comparing %q %q %v\n", verb, c, thousand[i])
 			if c == verb {
-				//fmt.Printf("%v / %v\n", v, (&big.Float{}).SetBytes(thousand[i+1], []byte{}))
-				v.Quo(v, (&big.Float{}).SetBytes(thousand[i], []byte{}))
-				if i < 10 {
+				div = thousand[i]
+				if i < 10 && jedec {
+					suf = string(thousandVerb[i]) + suf
+				} else if i < 10 {
 					suf = string(thousandVerb[i+20]) + suf
 				} else {
 					suf = string(thousandVerb[i%10]) + "i" + suf
@@ -144,6 +167,390 @@ func formatByte(b []byte, scale float64, f fmt.State, verb, def rune, suf string
 			return
 		}
 	}
-	v.Format(f, 'g')
+	if div != nil {
+		if v != nil {
+			v.Quo(v, (&big.Float{}).SetBytes(div, []byte{}))
+		} else {
+			r.Quo(r, (&big.Rat{}).SetInt((&big.Int{}).SetBytes(div)))
+		}
+	}
+	if verb == 's' || verb == 'S' {
+		if v != nil {
+			suf = loc.longName(word, def, suf, v)
+		} else {
+			suf = loc.longName(word, def, suf, (&big.Float{}).SetRat(r))
+		}
+	}
+	if loc != nil && def == 'B' && verb != 's' && verb != 'S' {
+		suf = strings.Replace(suf, "B", loc.byteSymbol(), 1)
+	}
+
+	switch {
+	case v == nil:
+		loc.writeText(f, formatRat(f, r, neg))
+	case neg:
+		v.Neg(v)
+		fallthrough
+	default:
+		if loc == nil {
+			v.Format(f, 'g')
+		} else {
+			loc.writeNumber(f, v)
+		}
+	}
 	f.Write([]byte(suf))
 }
+
+// formatRat writes the non-negative r as the %g verb does, rounding once to
+// the precision of f or to rateDigits significant digits
+func formatRat(f fmt.State, r *big.Rat, neg bool) string {
+	sign := ""
+	switch {
+	case neg && r.Sign() != 0:
+		sign = "-"
+	case f.Flag('+'):
+		sign = "+"
+	case f.Flag(' '):
+		sign = " "
+	}
+	if r.Sign() == 0 {
+		return sign + "0"
+	}
+	p, ok := f.Precision()
+	if !ok {
+		p = rateDigits
+	} else if p == 0 {
+		p = 1
+	}
+
+	// Round to p significant digits, half way rounds to even
+	dp := magnitude(r)
+	num, den := (&big.Int{}).Set(r.Num()), (&big.Int{}).Set(r.Denom())
+	if shift := p - dp; shift >= 0 {
+		num.Mul(num, (&big.Int{}).Exp(big.NewInt(10), big.NewInt(int64(shift)), nil))
+	} else {
+		den.Mul(den, (&big.Int{}).Exp(big.NewInt(10), big.NewInt(int64(-shift)), nil))
+	}
+	m, rem := num.QuoRem(num, den, &big.Int{})
+	if c := rem.Lsh(rem, 1).Cmp(den); c > 0 || c == 0 && m.Bit(0) == 1 {
+		m.Add(m, big.NewInt(1))
+	}
+	ds := m.String()
+	if len(ds) > p {
+		// Rounded up to the next power of ten
+		ds, dp = ds[:p], dp+1
+	}
+	ds = strings.TrimRight(ds, "0")
+	nd := len(ds)
+
+	eprec := p
+	if eprec > nd && nd >= dp {
+		eprec = nd
+	}
+	if !ok {
+		eprec = 6
+	}
+	if exp := dp - 1; exp < -4 || exp >= eprec {
+		s := sign + ds[:1]
+		if nd > 1 {
+			s += "." + ds[1:]
+		}
+		if exp < 0 {
+			s, exp = s+"e-", -exp
+		} else {
+			s += "e+"
+		}
+		if exp < 10 {
+			s += "0"
+		}
+		return s + strconv.Itoa(exp)
+	}
+	switch {
+	case dp <= 0:
+		return sign + "0." + strings.Repeat("0", -dp) + ds
+	case dp >= nd:
+		return sign + ds + strings.Repeat("0", dp-nd)
+	}
+	return sign + ds[:dp] + "." + ds[dp:]
+}
+
+// Prefix is the power of the base a Formatter scales a value by, from
+// PrefixKilo for base^1 to PrefixQuetta for base^10
+type Prefix int
+
+const (
+	PrefixNone Prefix = iota - 1 // No prefix, as a MaxPrefix this keeps plain units
+	_
+	PrefixKilo
+	PrefixMega
+	PrefixGiga
+	PrefixTera
+	PrefixPeta
+	PrefixExa
+	PrefixZetta
+	PrefixYotta
+	PrefixRonna
+	PrefixQuetta
+)
+
+// RateStyle selects how a Formatter writes the per second of a rate
+type RateStyle int
+
+const (
+	RateDefault RateStyle = iota // "B/s" for bytes and "bps" for bits, as with Printf
+	RateSlash                    // "B/s" and "b/s"
+	RatePer                      // "Bps" and "bps"
+)
+
+// Formatter writes values with a fixed set of options, for when the Printf
+// verbs are not enough.  Format and Append take any of Bytes, Bits, ByteRate
+// and BitRate or a pointer to one.
+type Formatter struct {
+	Base        int          // 1000 or 1024, 1000 when zero
+	JEDEC       bool         // Label powers of 1024 as KB, MB, GB
+	MinPrefix   Prefix       // Smallest prefix to use
+	MaxPrefix   Prefix       // Largest prefix to use, any when zero
+	Precision   int          // Digits after the decimal point, as many as needed when zero or negative
+	Significant int          // Significant digits, used in place of Precision when set
+	Whole       bool         // Round to a whole number of the unit, used in place of Precision when set
+	Trim        bool         // Drop trailing zeros after the decimal point
+	Rounding    RoundingMode // How the digits past the precision are dropped, nearest when zero, RoundExact keeps them
+	Space       bool         // Put a space between the number and the unit
+	Long        bool         // Write long names such as "Kilobytes"
+	Names       *Names       // Table for long names, NamesEnglish when nil
+	Locale      *Locale      // Separators and byte symbol, plain when nil
+	RateStyle   RateStyle
+	TimeBase    time.Duration // Time unit rates are written in, such as Day or Month, per second when zero
+}
+
+// DefaultFormatter writes values like the %v verb
+var DefaultFormatter = Formatter{}
+
+// Format returns v written with the options of the Formatter
+func (f *Formatter) Format(v interface{}) string {
+	return string(f.Append(nil, v))
+}
+
+// Append appends v written with the options of the Formatter to dst and
+// returns the extended buffer
+func (f *Formatter) Append(dst []byte, v interface{}) []byte {
+	switch p := v.(type) {
+	case *Bytes:
+		v = *p
+	case *Bits:
+		v = *p
+	case *ByteRate:
+		v = *p
+	case *BitRate:
+		v = *p
+	}
+	switch b := v.(type) {
+	case Bytes:
+		return f.append(dst, (&big.Rat{}).SetInt(intBytes(b.num)), true, "")
+	case Bits:
+		return f.append(dst, (&big.Rat{}).SetInt(intBytes(b.num)), false, "")
+	case ByteRate:
+		return f.append(dst, f.inBase(b.n.num, b.d), true, timeLabel(f.TimeBase))
+	case BitRate:
+		return f.append(dst, f.inBase(b.n.num, b.d), false, timeLabel(f.TimeBase))
+	}
+	return append(dst, fmt.Sprintf("%%!(%T)", v)...)
+}
+
+// inBase gives the exact rate of n over d in the time base of the Formatter
+func (f *Formatter) inBase(n num, d time.Duration) *big.Rat {
+	r := ratePerSecond(n, d)
+	if f.TimeBase > 0 {
+		r.Mul(r, big.NewRat(int64(f.TimeBase), int64(time.Second)))
+	}
+	return r
+}
+
+func (f *Formatter) append(dst []byte, r *big.Rat, isByte bool, per string) []byte {
+	base := big.NewInt(1000)
+	if f.Base == 1024 {
+		base = big.NewInt(1024)
+	}
+	lo, hi := int(f.MinPrefix), int(f.MaxPrefix)
+	if lo < 0 {
+		lo = 0
+	} else if lo > 10 {
+		lo = 10
+	}
+	if hi == 0 || hi > 10 {
+		hi = 10
+	} else if hi < lo {
+		hi = lo
+	}
+	power := func(k int) *big.Rat {
+		return (&big.Rat{}).SetInt((&big.Int{}).Exp(base, big.NewInt(int64(k)), nil))
+	}
+	abs := (&big.Rat{}).Abs(r)
+	k := lo
+	for k < hi && abs.Cmp(power(k+1)) >= 0 {
+		k++
+	}
+	num, rounded := f.round((&big.Rat{}).Quo(r, power(k)))
+	if k < hi && (&big.Rat{}).Abs(rounded).Cmp((&big.Rat{}).SetInt(base)) >= 0 {
+		// Rounding carried over into the next prefix
+		k++
+		num, rounded = f.round((&big.Rat{}).Quo(r, power(k)))
+	}
+
+	// Group the digits without the sign
+	if num[0] == '-' {
+		dst, num = append(dst, '-'), num[1:]
+	}
+	dst = append(dst, f.Locale.number(num)...)
+	if f.Space {
+		dst = append(dst, ' ')
+	}
+	if f.Long {
+		names := f.Names
+		if names == nil {
+			names = NamesEnglish
+		}
+		word := -1
+		if k > 0 {
+			word = k - 1
+			if f.Base == 1024 && !f.JEDEC {
+				word += 10
+			}
+		}
+		return append(dst, names.name(word, isByte, per, names.plural((&big.Float{}).SetRat(rounded)))...)
+	}
+	if k > 0 {
+		if f.Base == 1024 {
+			dst = append(dst, thousandVerb[k-1])
+			if !f.JEDEC {
+				dst = append(dst, 'i')
+			}
+		} else {
+			dst = append(dst, thousandVerb[20+k-1])
+		}
+	}
+	if isByte {
+		dst = append(dst, f.Locale.byteSymbol()...)
+	} else {
+		dst = append(dst, 'b')
+	}
+	switch {
+	case per == "":
+	case per == "s" && (isByte && f.RateStyle == RatePer || !isByte && f.RateStyle != RateSlash):
+		dst = append(dst, "ps"...)
+	default:
+		dst = append(dst, '/')
+		dst = append(dst, per...)
+	}
+	return dst
+}
+
+// round writes x as a plain decimal with the precision of the Formatter and
+// gives the value which was written
+func (f *Formatter) round(x *big.Rat) (string, *big.Rat) {
+	neg := x.Sign() < 0
+	x = (&big.Rat{}).Abs(x)
+	mode, trim := f.Rounding, f.Trim
+	var p int
+	switch {
+	case f.Significant > 0 && mode != RoundExact:
+		if x.Sign() != 0 {
+			p = f.Significant - magnitude(x)
+		}
+	case f.Whole && mode != RoundExact:
+	case f.Precision > 0 && mode != RoundExact:
+		p = f.Precision
+	default:
+		// As many digits as needed, non terminating values get ten significant
+		// digits
+		trim = true
+		var ok bool
+		if p, ok = decimalPlaces(x.Denom()); !ok {
+			p = 10 - magnitude(x)
+			if mode == RoundExact {
+				mode = RoundNearest
+			}
+		}
+	}
+
+	num, den := (&big.Int{}).Set(x.Num()), (&big.Int{}).Set(x.Denom())
+	scale := (&big.Int{}).Exp(big.NewInt(10), big.NewInt(abs(int64(p))), nil)
+	if p >= 0 {
+		num.Mul(num, scale)
+	} else {
+		den.Mul(den, scale)
+	}
+	m, rem := num.QuoRem(num, den, &big.Int{})
+	if rem.Sign() != 0 {
+		switch mode {
+		case RoundCeil:
+			m.Add(m, big.NewInt(1))
+		case RoundNearest:
+			if rem.Lsh(rem, 1).Cmp(den) >= 0 {
+				m.Add(m, big.NewInt(1))
+			}
+		}
+	}
+
+	var rounded *big.Rat
+	s := m.String()
+	if p > 0 {
+		rounded = (&big.Rat{}).SetFrac(m, scale)
+		if len(s) <= p {
+			s = strings.Repeat("0", p-len(s)+1) + s
+		}
+		s = s[:len(s)-p] + "." + s[len(s)-p:]
+		if trim {
+			s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+		}
+	} else {
+		rounded = (&big.Rat{}).SetInt((&big.Int{}).Mul(m, scale))
+		if p < 0 && m.Sign() != 0 {
+			s += strings.Repeat("0", -p)
+		}
+	}
+	if neg && rounded.Sign() != 0 {
+		return "-" + s, rounded.Neg(rounded)
+	}
+	return s, rounded
+}
+
+// magnitude gives the number of digits before the decimal point of x, or the
+// negative count of zeros after it for values under one
+func magnitude(x *big.Rat) int {
+	if x.Sign() == 0 {
+		return 1
+	}
+	if i := ratInt(x); i.Sign() > 0 {
+		return len(i.String())
+	}
+	e := 0
+	for y := (&big.Rat{}).Set(x); y.Cmp(big.NewRat(1, 10)) < 0; e-- {
+		y.Mul(y, big.NewRat(10, 1))
+	}
+	return e
+}
+
+// decimalPlaces gives the digits needed after the decimal point to write a
+// fraction with the denominator d, ok is false when the digits never end
+func decimalPlaces(d *big.Int) (p int, ok bool) {
+	d = (&big.Int{}).Set(d)
+	two, five := 0, 0
+	for d.Bit(0) == 0 {
+		d.Rsh(d, 1)
+		two++
+	}
+	m := &big.Int{}
+	for {
+		q, r := (&big.Int{}).QuoRem(d, big.NewInt(5), m)
+		if r.Sign() != 0 {
+			break
+		}
+		d = q
+		five++
+	}
+	if two < five {
+		two = five
+	}
+	return two, d.Cmp(big.NewInt(1)) == 0
+}
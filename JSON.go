@@ -0,0 +1,205 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/cymertek/go-big"
+)
+
+// JSONMode selects how values are written by MarshalJSON
+type JSONMode int
+
+const (
+	JSONString JSONMode = iota // Write the MarshalText form as a JSON string, like "512MiB"
+	JSONNumber                 // Write the exact integer as a JSON number, like 536870912
+)
+
+// MarshalJSONMode controls the output of MarshalJSON for all the unit types.
+// With JSONNumber sizes are written as the exact number of bytes or bits and
+// rates as the number per second, a rate which does not work out to a whole
+// number per second is written as a string instead.
+var MarshalJSONMode = JSONString
+
+// MarshalJSON implements the json.Marshaler interface
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	if MarshalJSONMode == JSONNumber {
+		return []byte(exactString(b.num)), nil
+	}
+	txt, _ := b.MarshalText()
+	return json.Marshal(string(txt))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Either a JSON
+// number of whole bytes or a string accepted by ParseBytes may be given.
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	txt, num, err := unmarshalJSON(data)
+	switch {
+	case err != nil:
+		return err
+	case num != nil:
+		n, err := wholeNumber(num, txt, "bytes")
+		if err != nil {
+			return err
+		}
+		*b = Bytes{n}
+	case txt != "":
+		return b.UnmarshalText([]byte(txt))
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (b Bits) MarshalJSON() ([]byte, error) {
+	if MarshalJSONMode == JSONNumber {
+		return []byte(exactString(b.num)), nil
+	}
+	txt, _ := b.MarshalText()
+	return json.Marshal(string(txt))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Either a JSON
+// number of whole bits or a string accepted by ParseBits may be given.
+func (b *Bits) UnmarshalJSON(data []byte) error {
+	txt, num, err := unmarshalJSON(data)
+	switch {
+	case err != nil:
+		return err
+	case num != nil:
+		n, err := wholeNumber(num, txt, "bits")
+		if err != nil {
+			return err
+		}
+		*b = Bits{n}
+	case txt != "":
+		return b.UnmarshalText([]byte(txt))
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (b ByteRate) MarshalJSON() ([]byte, error) {
+	if MarshalJSONMode == JSONNumber {
+		if r := ratePerSecond(b.n.num, b.d); r.IsInt() {
+			return []byte(r.Num().String()), nil
+		}
+	}
+	txt, _ := b.MarshalText()
+	return json.Marshal(string(txt))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Either a JSON
+// number of bytes per second or a string accepted by ParseByteRate may be
+// given.
+func (b *ByteRate) UnmarshalJSON(data []byte) error {
+	txt, num, err := unmarshalJSON(data)
+	switch {
+	case err != nil:
+		return err
+	case num != nil:
+		n, d := rateFromRat(num)
+		*b = ByteRate{Bytes{n}, d}
+	case txt != "":
+		return b.UnmarshalText([]byte(txt))
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (b BitRate) MarshalJSON() ([]byte, error) {
+	if MarshalJSONMode == JSONNumber {
+		if r := ratePerSecond(b.n.num, b.d); r.IsInt() {
+			return []byte(r.Num().String()), nil
+		}
+	}
+	txt, _ := b.MarshalText()
+	return json.Marshal(string(txt))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Either a JSON
+// number of bits per second or a string accepted by ParseBitRate may be
+// given.
+func (b *BitRate) UnmarshalJSON(data []byte) error {
+	txt, num, err := unmarshalJSON(data)
+	switch {
+	case err != nil:
+		return err
+	case num != nil:
+		n, d := rateFromRat(num)
+		*b = BitRate{Bits{n}, d}
+	case txt != "":
+		return b.UnmarshalText([]byte(txt))
+	}
+	return nil
+}
+
+// unmarshalJSON splits a JSON value into either a string or an exact number
+// along with its text, a JSON null gives neither.
+func unmarshalJSON(data []byte) (txt string, num *big.Rat, err error) {
+	if len(data) == 0 {
+		return "", nil, errors.New("binary unit: empty JSON value")
+	}
+	switch c := data[0]; {
+	case c == 'n' && string(data) == "null":
+		return "", nil, nil
+	case c == '"':
+		if err = json.Unmarshal(data, &txt); err != nil {
+			return "", nil, err
+		}
+		if txt == "" {
+			return "", nil, errors.New("binary unit: invalid value " + quote(txt))
+		}
+		return txt, nil, nil
+	case c == '-' || '0' <= c && c <= '9':
+		num, err = exactNumber(string(data))
+		return string(data), num, err
+	}
+	return "", nil, errors.New("binary unit: invalid JSON value " + quote(string(data)))
+}
+
+// ratInt gives the integer portion of a rational, truncated towards zero
+func ratInt(r *big.Rat) *big.Int {
+	return (&big.Int{}).Quo(r.Num(), r.Denom())
+}
+
+// ratePerSecond gives the exact rate of n over d per second
+func ratePerSecond(n num, d time.Duration) *big.Rat {
+	if d == 0 {
+		return &big.Rat{}
+	}
+	num := intBytes(n)
+	num.Mul(num, big.NewInt(int64(time.Second)))
+	return (&big.Rat{}).SetFrac(num, big.NewInt(int64(d)))
+}
+
+// rateFromRat turns a per second rate into a numerator and duration, keeping
+// the value exact when the denominator is small enough to fit in a duration
+// and otherwise rounding to the nearest billionth of a unit per second.
+func rateFromRat(r *big.Rat) (num, time.Duration) {
+	if r.Denom().IsInt64() && r.Denom().Int64() <= (1<<63-1)/int64(time.Second) {
+		return bytesInt(r.Num()), time.Duration(r.Denom().Int64()) * time.Second
+	}
+	const scale = 1e9
+	n := (&big.Rat{}).Mul(r, big.NewRat(scale, 1))
+	if n.Sign() < 0 {
+		n.Sub(n, big.NewRat(1, 2))
+	} else {
+		n.Add(n, big.NewRat(1, 2))
+	}
+	return bytesInt(ratInt(n)), scale * time.Second
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"strconv"
+
+	"github.com/cymertek/go-big"
+)
+
+// KubeFormat is the style of a Kubernetes resource quantity
+type KubeFormat int
+
+const (
+	KubeDecimalSI       KubeFormat = iota // Like "100k" or "1500M"
+	KubeBinarySI                          // Like "512Mi" or "1536Mi"
+	KubeDecimalExponent                   // Like "2e9" or "1500e3"
+)
+
+var kubeBinary = []string{"", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+var kubeDecimal = []string{"", "k", "M", "G", "T", "P", "E"}
+
+// ParseKubeQuantity parses a Kubernetes resource quantity, such as "512Mi",
+// "1.5Gi", "2e9" or "100k", into a number of bytes along with the format it
+// was written in.  As with Kubernetes, fractional bytes are rounded away
+// from zero and values past 2^63-1 bytes are refused with ErrOverflow.
+func ParseKubeQuantity(s string) (Bytes, KubeFormat, error) {
+	orig := s
+	neg := false
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	// Consume the number
+	i, pt := 0, false
+	for ; i < len(s); i++ {
+		if c := s[i]; c == '.' && !pt {
+			pt = true
+		} else if c < '0' || c > '9' {
+			break
+		}
+	}
+	num, suffix := s[:i], s[i:]
+	if num == "" || num == "." {
+		return Bytes{}, 0, parseError(orig, len(orig)-len(s), num, ErrBadNumber, "invalid value "+quote(orig))
+	}
+	v, ok := (&big.Rat{}).SetString(num)
+	if !ok {
+		return Bytes{}, 0, parseError(orig, len(orig)-len(s), num, ErrBadNumber, "invalid value "+quote(orig))
+	}
+
+	// Consume the suffix
+	format := KubeDecimalSI
+	scale := big.NewInt(1)
+	bin, dec := indexOf(kubeBinary, suffix), indexOf(kubeDecimal, suffix)
+	switch {
+	case suffix == "":
+	case suffix == "m":
+		v.Quo(v, big.NewRat(1000, 1))
+	case bin > 0:
+		format = KubeBinarySI
+		scale.Lsh(scale, uint(10*bin))
+	case dec > 0:
+		scale.Exp(big.NewInt(10), big.NewInt(int64(3*dec)), nil)
+	case len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E'):
+		exp, err := strconv.ParseInt(suffix[1:], 10, 32)
+		if err != nil {
+			return Bytes{}, 0, parseError(orig, len(orig)-len(suffix), suffix, ErrBadNumber, "invalid exponent "+quote(suffix)+" in value "+quote(orig))
+		}
+		if abs(exp) > maxExponent {
+			return Bytes{}, 0, parseError(orig, len(orig)-len(suffix), suffix, ErrOverflow, "exponent too large in value "+quote(orig))
+		}
+		format = KubeDecimalExponent
+		p := (&big.Int{}).Exp(big.NewInt(10), big.NewInt(abs(exp)), nil)
+		if exp < 0 {
+			v.Quo(v, (&big.Rat{}).SetInt(p))
+		} else {
+			scale = p
+		}
+	default:
+		return Bytes{}, 0, parseError(orig, len(orig)-len(suffix), suffix, ErrUnknownUnit, "unknown unit "+quote(suffix)+" in value "+quote(orig))
+	}
+	v.Mul(v, (&big.Rat{}).SetInt(scale))
+
+	// Round up to a whole byte
+	n := (&big.Int{}).Quo(v.Num(), v.Denom())
+	if !v.IsInt() {
+		n.Add(n, big.NewInt(1))
+	}
+	// Kubernetes holds a quantity in 63 bits
+	if !n.IsInt64() {
+		return Bytes{}, 0, parseError(orig, 0, orig, ErrOverflow, "value "+quote(orig)+" is too large for a quantity")
+	}
+	if neg {
+		n.Neg(n)
+	}
+	return Bytes{bytesInt(n)}, format, nil
+}
+
+// FormatKubeQuantity writes b as a Kubernetes resource quantity in the
+// canonical form for the format, being the largest suffix which keeps an
+// integer mantissa.  Values under 1024 or not a whole number of Ki are given
+// in KubeDecimalSI in place of KubeBinarySI, as Kubernetes does.
+func FormatKubeQuantity(b Bytes, format KubeFormat) string {
+	if isNeg(b.num) {
+		return "-" + FormatKubeQuantity(b.Abs(), format)
+	}
+	n := (&big.Int{}).SetBytes(b.mag)
+	if n.Sign() == 0 {
+		return "0"
+	}
+	if format == KubeBinarySI && n.Cmp(big.NewInt(1024)) >= 0 {
+		k := 0
+		for ; k < len(kubeBinary)-1 && n.TrailingZeroBits() >= 10; k++ {
+			n.Rsh(n, 10)
+		}
+		if k > 0 {
+			return n.String() + kubeBinary[k]
+		}
+		format = KubeDecimalSI
+	}
+
+	// Remove factors of 1000 while the mantissa stays whole
+	k, r := 0, &big.Int{}
+	thousand := big.NewInt(1000)
+	for k < len(kubeDecimal)-1 || format == KubeDecimalExponent {
+		q, _ := (&big.Int{}).QuoRem(n, thousand, r)
+		if r.Sign() != 0 {
+			break
+		}
+		n, k = q, k+1
+	}
+	if format == KubeDecimalExponent {
+		if k == 0 {
+			return n.String()
+		}
+		return n.String() + "e" + strconv.Itoa(3*k)
+	}
+	return n.String() + kubeDecimal[k]
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func abs(i int64) int64 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
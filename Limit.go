@@ -0,0 +1,286 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket holding up to the burst size in bytes and filled
+// at the given rate.  A Limiter is safe for concurrent use and the rate may be
+// changed while others are waiting.
+type Limiter struct {
+	mu      sync.Mutex
+	rate    float64 // bytes per second, +Inf for no limit
+	burst   float64
+	tokens  float64
+	window  time.Duration // duration of the rate, a zero burst is taken over it
+	last    time.Time
+	changed chan struct{} // closed to wake waiters when the rate changes
+	now     func() time.Time
+	after   func(time.Duration) <-chan time.Time // nil to wait on a time.Timer
+}
+
+// NewLimiter returns a Limiter filling at rate with room for burst bytes.  A
+// nil rate does not limit and a zero burst is taken from the rate, so
+// "1MB/100ms" allows a burst of 1MB.  A zero rate lets nothing through until
+// the rate is changed or the context of the wait is done.
+func NewLimiter(rate *ByteRate, burst Bytes) *Limiter {
+	return NewLimiterWithClock(rate, burst, time.Now, nil)
+}
+
+// NewLimiterWithClock is like NewLimiter but reads the time from now and
+// waits on the channels given by after, such as time.After, allowing for
+// deterministic tests.  A nil after waits with a time.Timer.
+func NewLimiterWithClock(rate *ByteRate, burst Bytes, now func() time.Time, after func(time.Duration) <-chan time.Time) *Limiter {
+	if rate == nil {
+		return newLimiter(math.Inf(1), 0, burst, now, after)
+	}
+	return newLimiter(byteRateFloat(rate.n.num, rate.d), rate.d, burst, now, after)
+}
+
+func newLimiter(rate float64, d time.Duration, burst Bytes, now func() time.Time, after func(time.Duration) <-chan time.Time) *Limiter {
+	l := &Limiter{last: now(), changed: make(chan struct{}), now: now, after: after}
+	l.set(rate, d, burst)
+	if rate > 0 {
+		l.tokens = l.burst
+	}
+	return l
+}
+
+// SetRate changes the fill rate, a nil rate does not limit
+func (l *Limiter) SetRate(rate *ByteRate) {
+	if rate == nil {
+		l.setRate(math.Inf(1))
+	} else {
+		l.setRate(byteRateFloat(rate.n.num, rate.d))
+	}
+}
+
+// SetBitRate changes the fill rate, a nil rate does not limit
+func (l *Limiter) SetBitRate(rate *BitRate) {
+	if rate == nil {
+		l.setRate(math.Inf(1))
+	} else {
+		l.setRate(byteRateFloat(rate.n.num, rate.d) / 8)
+	}
+}
+
+// SetBurst changes the size of the bucket
+func (l *Limiter) SetBurst(burst Bytes) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fill()
+	l.set(l.rate, l.window, burst)
+}
+
+// SetClock changes where the time is read from and how waits are made, as
+// for NewLimiterWithClock, such as for a LimitReader under test
+func (l *Limiter) SetClock(now func() time.Time, after func(time.Duration) <-chan time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fill()
+	l.now, l.after, l.last = now, after, now()
+}
+
+// Burst gives the size of the bucket
+func (l *Limiter) Burst() Bytes {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return *NewBytes(int64(l.burst))
+}
+
+// WaitN blocks until n bytes are allowed or the context is done, more than
+// the burst size is taken one burst at a time
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	for n > 0 {
+		l.mu.Lock()
+		l.fill()
+		chunk := float64(n)
+		if chunk > l.burst {
+			chunk = l.burst
+		}
+		if l.tokens >= chunk {
+			l.tokens -= chunk
+			l.mu.Unlock()
+			n -= int(chunk)
+			continue
+		}
+		// Wait for the tokens to be earned or for the rate to change
+		var timer *time.Timer
+		var fire <-chan time.Time
+		if l.rate > 0 {
+			// A very low rate may wait longer than a Duration holds
+			wait := maxDuration
+			if w := (chunk - l.tokens) / l.rate * float64(time.Second); w < float64(maxDuration) {
+				wait = time.Duration(w)
+			}
+			if l.after != nil {
+				fire = l.after(wait)
+			} else {
+				timer = time.NewTimer(wait)
+				fire = timer.C
+			}
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-fire:
+		case <-changed:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Limiter) setRate(rate float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fill()
+	l.rate = rate
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// set the rate and burst, a zero burst is the amount moved in d capped at one
+// second worth and at least one byte
+func (l *Limiter) set(rate float64, d time.Duration, burst Bytes) {
+	l.rate, l.window = rate, d
+	l.burst = float64(burst.Int64())
+	switch {
+	case l.burst > 0:
+	case math.IsInf(rate, 1):
+		// Without a limit the burst only sets the size of each read or write
+		l.burst = 32 << 10
+	default:
+		if d > time.Second {
+			d = time.Second
+		}
+		l.burst = math.Max(1, math.Round(rate*d.Seconds()))
+	}
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// fill adds the tokens earned since the last call
+func (l *Limiter) fill() {
+	now := l.now()
+	if math.IsInf(l.rate, 1) {
+		l.tokens = l.burst
+	} else if l.tokens += now.Sub(l.last).Seconds() * l.rate; l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+func byteRateFloat(n num, d time.Duration) float64 {
+	if d <= 0 || isNeg(n) {
+		return 0
+	}
+	f, _ := ratePerSecond(n, d).Float64()
+	return f
+}
+
+// LimitedReader reads from the underlying io.Reader no faster than the
+// Limiter allows
+type LimitedReader struct {
+	*Limiter
+	r   io.Reader
+	ctx context.Context
+}
+
+// LimitedWriter writes to the underlying io.Writer no faster than the Limiter
+// allows
+type LimitedWriter struct {
+	*Limiter
+	w   io.Writer
+	ctx context.Context
+}
+
+// LimitReader returns a reader limited to rate, the rate may be given as
+// something like "1MB/100ms" to allow bursts of 1MB
+func LimitReader(r io.Reader, rate *ByteRate) *LimitedReader {
+	return LimitReaderContext(context.Background(), r, rate)
+}
+
+// LimitReaderContext is like LimitReader but stops waiting with an error once
+// ctx is done
+func LimitReaderContext(ctx context.Context, r io.Reader, rate *ByteRate) *LimitedReader {
+	return &LimitedReader{NewLimiter(rate, Bytes{}), r, ctx}
+}
+
+// LimitWriter returns a writer limited to rate
+func LimitWriter(w io.Writer, rate *BitRate) *LimitedWriter {
+	return LimitWriterContext(context.Background(), w, rate)
+}
+
+// LimitWriterContext is like LimitWriter but stops waiting with an error once
+// ctx is done
+func LimitWriterContext(ctx context.Context, w io.Writer, rate *BitRate) *LimitedWriter {
+	if rate == nil {
+		return &LimitedWriter{NewLimiter(nil, Bytes{}), w, ctx}
+	}
+	l := newLimiter(byteRateFloat(rate.n.num, rate.d)/8, rate.d, Bytes{}, time.Now, nil)
+	return &LimitedWriter{l, w, ctx}
+}
+
+// Read implements the io.Reader interface, reads are no larger than the burst
+// and wait for the bytes read to be allowed
+func (l *LimitedReader) Read(p []byte) (int, error) {
+	if b := int(l.Burst().Int64()); len(p) > b {
+		p = p[:b]
+	}
+	n, err := l.r.Read(p)
+	if n > 0 {
+		if werr := l.WaitN(l.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// Write implements the io.Writer interface, writing in chunks no larger than
+// the burst after each is allowed
+func (l *LimitedWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := p
+		if b := int(l.Burst().Int64()); len(chunk) > b {
+			chunk = chunk[:b]
+		}
+		if err = l.WaitN(l.ctx, len(chunk)); err != nil {
+			return
+		}
+		var m int
+		m, err = l.w.Write(chunk)
+		n += m
+		if err != nil {
+			return
+		}
+		p = p[m:]
+	}
+	return
+}
@@ -0,0 +1,242 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cymertek/go-big"
+)
+
+// Locale holds the regional conventions for writing and reading numbers and
+// units.  Use Wrap to format a value with a Locale, or set the Locale of a
+// Parser to read values written with it.
+type Locale struct {
+	Decimal    string // Decimal separator, "." when empty
+	Group      string // Digit grouping separator, no grouping when empty
+	ByteSymbol string // Short byte unit, "B" when empty
+	Names      *Names // Long names used by %s and %S, "KiloByte" style when nil
+}
+
+var (
+	LocaleEnglish = &Locale{Decimal: ".", Group: ",", Names: NamesEnglish}
+	LocaleFrench  = &Locale{Decimal: ",", Group: " ", ByteSymbol: "o", Names: NamesFrench}
+	LocaleGerman  = &Locale{Decimal: ",", Group: ".", Names: NamesGerman}
+	LocaleSpanish = &Locale{Decimal: ",", Group: ".", Names: NamesSpanish}
+)
+
+// Wrap returns a fmt.Formatter which prints v, one of the unit types of this
+// package, with the same verbs but following the Locale
+func (l *Locale) Wrap(v interface{}) fmt.Formatter {
+	return localized{v, l}
+}
+
+type localized struct {
+	v interface{}
+	l *Locale
+}
+
+// Format for use in Printf
+func (x localized) Format(f fmt.State, verb rune) {
+	switch b := x.v.(type) {
+	case Bytes:
+		formatByte(b.num, nil, f, verb, 'B', "B", x.l)
+	case *Bytes:
+		formatByte(b.num, nil, f, verb, 'B', "B", x.l)
+	case Bits:
+		formatByte(b.num, nil, f, verb, 'b', "b", x.l)
+	case *Bits:
+		formatByte(b.num, nil, f, verb, 'b', "b", x.l)
+	case rateIn:
+		b.format(f, verb, x.l)
+	case ByteRate:
+		formatByte(b.n.num, rateScale(b.d), f, verb, 'B', "B/s", x.l)
+	case *ByteRate:
+		formatByte(b.n.num, rateScale(b.d), f, verb, 'B', "B/s", x.l)
+	case BitRate:
+		formatByte(b.n.num, rateScale(b.d), f, verb, 'b', "bps", x.l)
+	case *BitRate:
+		formatByte(b.n.num, rateScale(b.d), f, verb, 'b', "bps", x.l)
+	default:
+		fmt.Fprintf(f, "%%!%c(%T)", verb, x.v)
+	}
+}
+
+// writeNumber prints v with the precision, flags and width of f using the
+// separators of the Locale
+func (l *Locale) writeNumber(f fmt.State, v *big.Float) {
+	spec := "%"
+	for _, c := range "+ " {
+		if f.Flag(int(c)) {
+			spec += string(c)
+		}
+	}
+	if p, ok := f.Precision(); ok {
+		spec += "." + strconv.Itoa(p)
+	}
+	l.writeText(f, fmt.Sprintf(spec+"g", v))
+}
+
+// writeText prints the formatted number s with the width of f using the
+// separators of the Locale, a nil Locale keeps the digits as they are
+func (l *Locale) writeText(f fmt.State, s string) {
+	// Split off the sign and the exponent, then separate the digits
+	i := strings.IndexAny(s, "0123456789")
+	sign, num, exp := s[:i], s[i:], ""
+	if e := strings.IndexAny(num, "eE"); e >= 0 {
+		num, exp = num[:e], num[e:]
+	}
+	s = sign + l.number(num) + exp
+
+	// Pad out to the width
+	if w, ok := f.Width(); ok && len(s) < w {
+		switch {
+		case f.Flag('-'):
+			s += strings.Repeat(" ", w-len(s))
+		case f.Flag('0'):
+			s = sign + strings.Repeat("0", w-len(s)) + s[len(sign):]
+		default:
+			s = strings.Repeat(" ", w-len(s)) + s
+		}
+	}
+	f.Write([]byte(s))
+}
+
+// number writes the plain decimal num with the separators of the Locale
+func (l *Locale) number(num string) string {
+	intPart, frac := num, ""
+	if d := strings.IndexByte(num, '.'); d >= 0 {
+		intPart, frac = num[:d], num[d+1:]
+	}
+	if l != nil && l.Group != "" {
+		for j := len(intPart) - 3; j > 0; j -= 3 {
+			intPart = intPart[:j] + l.Group + intPart[j:]
+		}
+	}
+	if frac != "" {
+		return intPart + l.decimal() + frac
+	}
+	return intPart
+}
+
+// normalize rewrites the separators of the Locale in s to those understood by
+// the parser.  Single byte separators are swapped in place so offsets into s
+// are kept.
+func (l *Locale) normalize(s string) string {
+	var out []byte
+	for i := 0; i < len(s); {
+		between := i > 0 && isDigit(s[i-1])
+		switch {
+		case l.Group != "" && between && strings.HasPrefix(s[i:], l.Group) &&
+			i+len(l.Group)+3 <= len(s) && isDigits(s[i+len(l.Group):i+len(l.Group)+3]):
+			out = append(out, '_')
+			i += len(l.Group)
+		case l.Decimal != "" && strings.HasPrefix(s[i:], l.Decimal) &&
+			i+len(l.Decimal) < len(s) && isDigit(s[i+len(l.Decimal)]):
+			out = append(out, '.')
+			i += len(l.Decimal)
+		default:
+			out = append(out, s[i])
+			i++
+		}
+	}
+	return string(out)
+}
+
+// baseIndex finds where a byte or bit unit named by the Locale starts at the
+// end of u, or -1 when none is found
+func (l *Locale) baseIndex(u string, fold bool) int {
+	for _, name := range l.names() {
+		if len(name) < len(u) && (strings.HasSuffix(u, name) || fold && strings.EqualFold(u[len(u)-len(name):], name)) {
+			return len(u) - len(name)
+		}
+		if name == u || fold && strings.EqualFold(name, u) {
+			return 0
+		}
+	}
+	return -1
+}
+
+// base reports if u is a byte or bit unit named by the Locale
+func (l *Locale) base(u string, fold bool) (isByte bool, ok bool) {
+	for i, name := range l.names() {
+		if name == u || fold && strings.EqualFold(name, u) {
+			return i < 3, true
+		}
+	}
+	return false, false
+}
+
+// names gives the byte names followed by the bit names, longest first
+func (l *Locale) names() []string {
+	if l.Names == nil {
+		return []string{"Bytes", "Byte", l.byteSymbol(), "Bits", "Bit"}
+	}
+	return []string{l.Names.Bytes, l.Names.Byte, l.byteSymbol(), l.Names.Bits, l.Names.Bit}
+}
+
+// longName gives the suffix for the %s and %S verbs with the prefix index, or
+// -1 for none
+func (l *Locale) longName(prefix int, def rune, suf string, v *big.Float) string {
+	if l == nil || l.Names == nil {
+		p := ""
+		if prefix >= 0 {
+			p = thousandWord[prefix]
+		}
+		switch suf {
+		case "b":
+			suf = "Bit"
+		case "B":
+			suf = "Byte"
+		}
+		return p + suf
+	}
+	per := ""
+	if i := strings.IndexByte(suf, '/'); i >= 0 {
+		per = suf[i+1:]
+	} else if suf != "b" && suf != "B" {
+		per = "s"
+	}
+	name := l.Names.name(prefix, def == 'B', per, l.Names.plural(v))
+	if l.Names.Space {
+		name = " " + name
+	}
+	return name
+}
+
+func (l *Locale) decimal() string {
+	if l == nil || l.Decimal == "" {
+		return "."
+	}
+	return l.Decimal
+}
+
+func (l *Locale) byteSymbol() string {
+	if l == nil || l.ByteSymbol == "" {
+		return "B"
+	}
+	return l.ByteSymbol
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
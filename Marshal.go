@@ -0,0 +1,151 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"time"
+
+	"github.com/cymertek/go-big"
+)
+
+// MarshalText implements the encoding.TextMarshaler interface.  The String()
+// form is used when it parses back to exactly the same value, otherwise the
+// exact number of bytes is given.
+func (b Bytes) MarshalText() ([]byte, error) {
+	s := b.String()
+	if v, err := ParseBytesStrict(s); err == nil && cmpBytes(v.num, b.num) == 0 {
+		return []byte(s), nil
+	}
+	return []byte(exactString(b.num) + "B"), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (b *Bytes) UnmarshalText(text []byte) error {
+	v, err := ParseBytes(string(text))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.  The String()
+// form is used when it parses back to exactly the same value, otherwise the
+// exact number of bits is given.
+func (b Bits) MarshalText() ([]byte, error) {
+	s := b.String()
+	if v, err := DefaultParser.parseBits(s); err == nil && v.IsInt() && cmpBytes(bytesInt(v.Num()), b.num) == 0 {
+		return []byte(s), nil
+	}
+	return []byte(exactString(b.num) + "b"), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (b *Bits) UnmarshalText(text []byte) error {
+	v, err := ParseBits(string(text))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.  The exact
+// amount is written over the time base of the rate, such as "1TB/mo" or "1.5GiB/2h",
+// which parses back to the same rate.
+func (b ByteRate) MarshalText() ([]byte, error) {
+	n, d := b.n, b.d
+	switch {
+	case d == 0:
+		n, d = Bytes{}, time.Second
+	case d < 0:
+		n, d = Bytes{negBytes(n.num)}, -d
+	}
+	return shortestText(n.num, "B", "/"+durationText(d), n.AppendFormat, func(s string) bool {
+		v, err := ParseByteRate(s)
+		return err == nil && v.Cmp(b) == 0
+	}), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (b *ByteRate) UnmarshalText(text []byte) error {
+	v, err := ParseByteRate(string(text))
+	if err != nil {
+		return err
+	}
+	*b = *v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.  The exact
+// amount is written over the time base of the rate, such as "8Tb/mo" or "5Mib/100ms",
+// which parses back to the same rate.
+func (b BitRate) MarshalText() ([]byte, error) {
+	n, d := b.n, b.d
+	switch {
+	case d == 0:
+		n, d = Bits{}, time.Second
+	case d < 0:
+		n, d = Bits{negBytes(n.num)}, -d
+	}
+	return shortestText(n.num, "b", "/"+durationText(d), n.AppendFormat, func(s string) bool {
+		v, err := ParseBitRate(s)
+		return err == nil && v.Cmp(b) == 0
+	}), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (b *BitRate) UnmarshalText(text []byte) error {
+	v, err := ParseBitRate(string(text))
+	if err != nil {
+		return err
+	}
+	*b = *v
+	return nil
+}
+
+// shortestText gives the shorter of the SI and IEC forms of x followed by suf
+// which exact reports as reading back without loss, otherwise the exact
+// integer followed by def and suf
+func shortestText(x num, def, suf string, format func([]byte, rune) []byte, exact func(string) bool) []byte {
+	var best []byte
+	for _, verb := range []rune{'v', 'V'} {
+		txt := append(format(nil, verb), suf...)
+		if best != nil && len(txt) >= len(best) {
+			continue
+		}
+		if exact(string(txt)) {
+			best = txt
+		}
+	}
+	if best == nil {
+		best = []byte(exactString(x) + def + suf)
+	}
+	return best
+}
+
+// exactString gives the decimal integer form of the byte slice
+func exactString(b num) string {
+	return intBytes(b).String()
+}
+
+// cmpRate compares the rates n1/d1 and n2/d2 without any loss of precision
+func cmpRate(n1 num, d1 time.Duration, n2 num, d2 time.Duration) int {
+	x := intBytes(n1)
+	x.Mul(x, big.NewInt(int64(d2)))
+	y := intBytes(n2)
+	y.Mul(y, big.NewInt(int64(d1)))
+	return x.Cmp(y)
+}
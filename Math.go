@@ -0,0 +1,189 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"github.com/cymertek/go-big"
+)
+
+// Add returns the sum b + o
+func (b Bytes) Add(o Bytes) Bytes { return Bytes{addBytes(b.num, o.num)} }
+
+// Add returns the sum b + o
+func (b Bits) Add(o Bits) Bits { return Bits{addBytes(b.num, o.num)} }
+
+// Sub returns the difference b - o, which is negative when o is larger
+func (b Bytes) Sub(o Bytes) Bytes { return Bytes{subBytes(b.num, o.num)} }
+
+// Sub returns the difference b - o, which is negative when o is larger
+func (b Bits) Sub(o Bits) Bits { return Bits{subBytes(b.num, o.num)} }
+
+// Mul returns the product b * n
+func (b Bytes) Mul(n int64) Bytes { return Bytes{mulBytes(b.num, n)} }
+
+// Mul returns the product b * n
+func (b Bits) Mul(n int64) Bits { return Bits{mulBytes(b.num, n)} }
+
+// Quo returns the quotient b / n truncated towards zero.  Quo panics if n is
+// zero.
+func (b Bytes) Quo(n int64) Bytes { return Bytes{quoBytes(b.num, n)} }
+
+// Quo returns the quotient b / n truncated towards zero.  Quo panics if n is
+// zero.
+func (b Bits) Quo(n int64) Bits { return Bits{quoBytes(b.num, n)} }
+
+// Mod returns the remainder of b / n, useful for block alignment, which takes
+// the sign of b.  Mod panics if n is zero.
+func (b Bytes) Mod(n int64) Bytes { return Bytes{modBytes(b.num, n)} }
+
+// Mod returns the remainder of b / n, useful for block alignment, which takes
+// the sign of b.  Mod panics if n is zero.
+func (b Bits) Mod(n int64) Bits { return Bits{modBytes(b.num, n)} }
+
+// Cmp compares b and o and returns -1 if b < o, 0 if b == o, +1 if b > o
+func (b Bytes) Cmp(o Bytes) int { return cmpBytes(b.num, o.num) }
+
+// Cmp compares b and o and returns -1 if b < o, 0 if b == o, +1 if b > o
+func (b Bits) Cmp(o Bits) int { return cmpBytes(b.num, o.num) }
+
+// Sign returns -1 if b is negative, 0 if b is zero and +1 if b is positive
+func (b Bytes) Sign() int { return signBytes(b.num) }
+
+// Sign returns -1 if b is negative, 0 if b is zero and +1 if b is positive
+func (b Bits) Sign() int { return signBytes(b.num) }
+
+// IsZero reports whether b holds the value zero
+func (b Bytes) IsZero() bool { return signBytes(b.num) == 0 }
+
+// IsZero reports whether b holds the value zero
+func (b Bits) IsZero() bool { return signBytes(b.num) == 0 }
+
+// Min returns a copy of the smaller of b and o
+func (b Bytes) Min(o Bytes) Bytes {
+	if cmpBytes(b.num, o.num) <= 0 {
+		return Bytes{copyBytes(b.num)}
+	}
+	return Bytes{copyBytes(o.num)}
+}
+
+// Min returns a copy of the smaller of b and o
+func (b Bits) Min(o Bits) Bits {
+	if cmpBytes(b.num, o.num) <= 0 {
+		return Bits{copyBytes(b.num)}
+	}
+	return Bits{copyBytes(o.num)}
+}
+
+// Max returns a copy of the larger of b and o
+func (b Bytes) Max(o Bytes) Bytes {
+	if cmpBytes(b.num, o.num) >= 0 {
+		return Bytes{copyBytes(b.num)}
+	}
+	return Bytes{copyBytes(o.num)}
+}
+
+// Max returns a copy of the larger of b and o
+func (b Bits) Max(o Bits) Bits {
+	if cmpBytes(b.num, o.num) >= 0 {
+		return Bits{copyBytes(b.num)}
+	}
+	return Bits{copyBytes(o.num)}
+}
+
+// Abs returns the magnitude of b
+func (b Bytes) Abs() Bytes { return Bytes{copyBytes(unsigned(b.mag))} }
+
+// Abs returns the magnitude of b
+func (b Bits) Abs() Bits { return Bits{copyBytes(unsigned(b.mag))} }
+
+// Neg returns the value -b
+func (b Bytes) Neg() Bytes { return Bytes{negBytes(b.num)} }
+
+// Neg returns the value -b
+func (b Bits) Neg() Bits { return Bits{negBytes(b.num)} }
+
+// isNeg reports if x holds a negative value
+func isNeg(x num) bool {
+	return signBytes(x) < 0
+}
+
+// intBytes reads the signed value held in x
+func intBytes(x num) *big.Int {
+	i := (&big.Int{}).SetBytes(x.mag)
+	if x.neg {
+		i.Neg(i)
+	}
+	return i
+}
+
+// bytesInt gives the signed form of i
+func bytesInt(i *big.Int) num {
+	return num{mag: i.Bytes(), neg: i.Sign() < 0}
+}
+
+// unsigned gives the value with the magnitude x
+func unsigned(x []byte) num {
+	return num{mag: x}
+}
+
+func addBytes(x, y num) num {
+	i := intBytes(x)
+	return bytesInt(i.Add(i, intBytes(y)))
+}
+
+func subBytes(x, y num) num {
+	i := intBytes(x)
+	return bytesInt(i.Sub(i, intBytes(y)))
+}
+
+func mulBytes(x num, n int64) num {
+	i := intBytes(x)
+	return bytesInt(i.Mul(i, big.NewInt(n)))
+}
+
+func quoBytes(x num, n int64) num {
+	i := intBytes(x)
+	return bytesInt(i.Quo(i, big.NewInt(n)))
+}
+
+func modBytes(x num, n int64) num {
+	i := intBytes(x)
+	return bytesInt(i.Rem(i, big.NewInt(n)))
+}
+
+func negBytes(x num) num {
+	i := intBytes(x)
+	return bytesInt(i.Neg(i))
+}
+
+func cmpBytes(x, y num) int {
+	return intBytes(x).Cmp(intBytes(y))
+}
+
+func signBytes(x num) int {
+	for _, c := range x.mag {
+		if c != 0 {
+			if x.neg {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func copyBytes(x num) num {
+	return num{mag: append([]byte{}, x.mag...), neg: x.neg}
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// MeterTick is the resolution of a Meter, counts are gathered into ticks of
+// this length before being folded into the averages.
+const MeterTick = 100 * time.Millisecond
+
+var meterTau = [3]time.Duration{time.Second, 5 * time.Second, 15 * time.Second}
+
+// Meter measures the throughput of byte counts given to Mark.  The rates
+// reported are the last tick, exponentially weighted moving averages over 1, 5
+// and 15 seconds, and a sliding window average.  A Meter is safe for
+// concurrent use.
+type Meter struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	last    time.Time  // start of the current tick
+	pending int64      // count in the current tick
+	instant int64      // count in the last complete tick
+	ewma    [3]float64 // bytes per second
+	window  []int64    // ring of complete tick counts
+	pos     int
+}
+
+// NewMeter returns a Meter with a sliding window average over window, which
+// is rounded up to a whole number of ticks.
+func NewMeter(window time.Duration) *Meter {
+	return NewMeterWithClock(window, time.Now)
+}
+
+// NewMeterWithClock is like NewMeter but reads the time from now, allowing
+// for deterministic tests.
+func NewMeterWithClock(window time.Duration, now func() time.Time) *Meter {
+	n := int((window + MeterTick - 1) / MeterTick)
+	if n < 1 {
+		n = 1
+	}
+	return &Meter{now: now, last: now(), window: make([]int64, n)}
+}
+
+// Mark records n bytes as moved
+func (m *Meter) Mark(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance()
+	m.pending += n
+}
+
+// Instant gives the rate over the last complete tick
+func (m *Meter) Instant() ByteRate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance()
+	return ByteRate{*NewBytes(m.instant), MeterTick}
+}
+
+// Rate1 gives the exponentially weighted moving average over 1 second
+func (m *Meter) Rate1() ByteRate { return m.rate(0) }
+
+// Rate5 gives the exponentially weighted moving average over 5 seconds
+func (m *Meter) Rate5() ByteRate { return m.rate(1) }
+
+// Rate15 gives the exponentially weighted moving average over 15 seconds
+func (m *Meter) Rate15() ByteRate { return m.rate(2) }
+
+// Window gives the average rate over the sliding window of complete ticks
+func (m *Meter) Window() ByteRate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance()
+	var sum int64
+	for _, c := range m.window {
+		sum += c
+	}
+	return ByteRate{*NewBytes(sum), time.Duration(len(m.window)) * MeterTick}
+}
+
+func (m *Meter) rate(i int) ByteRate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance()
+	// Keep a thousandth of a byte per second
+	return ByteRate{*NewBytes(int64(math.Round(m.ewma[i] * 1e3))), 1e3 * time.Second}
+}
+
+// advance folds in the ticks which have completed since the last call
+func (m *Meter) advance() {
+	k := int64(m.now().Sub(m.last) / MeterTick)
+	if k <= 0 {
+		return
+	}
+	m.last = m.last.Add(time.Duration(k) * MeterTick)
+
+	// The first tick holds the pending count and the rest are idle
+	r := float64(m.pending) / MeterTick.Seconds()
+	for i, tau := range meterTau {
+		decay := math.Exp(-float64(MeterTick) / float64(tau))
+		m.ewma[i] = m.ewma[i]*decay + r*(1-decay)
+		if k > 1 {
+			m.ewma[i] *= math.Pow(decay, float64(k-1))
+		}
+	}
+	m.instant = m.pending
+	if k > 1 {
+		m.instant = 0
+	}
+	m.window[m.pos] = m.pending
+	m.pos = (m.pos + 1) % len(m.window)
+	for j := int64(1); j < k && j <= int64(len(m.window)); j++ {
+		m.window[m.pos] = 0
+		m.pos = (m.pos + 1) % len(m.window)
+	}
+	m.pending = 0
+}
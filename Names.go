@@ -0,0 +1,113 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cymertek/go-big"
+)
+
+// Names is a table of the long unit names written by the %s and %S verbs.
+// Select a table with the Names of a Locale or with Names.Wrap.
+type Names struct {
+	Prefixes    [20]string // Kilo through Quetta followed by Kibi through Qubi
+	Byte, Bytes string     // Singular and plural byte names
+	Bit, Bits   string     // Singular and plural bit names
+	PerSecond   string     // Appended to rates per second, "/s" when empty
+
+	SingularBelowTwo bool // Values under two are singular, as in French, otherwise only one is
+	Capitalize       bool // Upper case the first letter of the name
+	Lower            bool // Lower case the whole name
+	Space            bool // Put a space between the number and the name
+}
+
+var (
+	NamesEnglish = &Names{
+		Prefixes: [20]string{"Kilo", "Mega", "Giga", "Tera", "Peta", "Exa", "Zetta", "Yotta", "Ronna", "Quetta",
+			"Kibi", "Mebi", "Gibi", "Tebi", "Pebi", "Exbi", "Zebi", "Yobi", "Robi", "Qubi"},
+		Byte: "byte", Bytes: "bytes", Bit: "bit", Bits: "bits",
+		Capitalize: true, Space: true,
+	}
+	NamesFrench = &Names{
+		Prefixes: [20]string{"kilo", "méga", "giga", "téra", "péta", "exa", "zetta", "yotta", "ronna", "quetta",
+			"kibi", "mébi", "gibi", "tébi", "pébi", "exbi", "zébi", "yobi", "robi", "quebi"},
+		Byte: "octet", Bytes: "octets", Bit: "bit", Bits: "bits",
+		SingularBelowTwo: true, Space: true,
+	}
+	NamesGerman = &Names{
+		Prefixes: [20]string{"Kilo", "Mega", "Giga", "Tera", "Peta", "Exa", "Zetta", "Yotta", "Ronna", "Quetta",
+			"Kibi", "Mebi", "Gibi", "Tebi", "Pebi", "Exbi", "Zebi", "Yobi", "Robi", "Qubi"},
+		Byte: "byte", Bytes: "byte", Bit: "bit", Bits: "bit",
+		Capitalize: true, Space: true,
+	}
+	NamesSpanish = &Names{
+		Prefixes: [20]string{"kilo", "mega", "giga", "tera", "peta", "exa", "zetta", "yotta", "ronna", "quetta",
+			"kibi", "mebi", "gibi", "tebi", "pebi", "exbi", "zebi", "yobi", "robi", "quebi"},
+		Byte: "byte", Bytes: "bytes", Bit: "bit", Bits: "bits",
+		Space: true,
+	}
+)
+
+// Wrap returns a fmt.Formatter which prints v, one of the unit types of this
+// package, using the long names of the table for %s and %S
+func (n *Names) Wrap(v interface{}) fmt.Formatter {
+	return localized{v, &Locale{Names: n}}
+}
+
+// plural reports if the value v takes the plural name
+func (n *Names) plural(v *big.Float) bool {
+	v = (&big.Float{}).Abs(v)
+	if n.SingularBelowTwo {
+		return v.Cmp(big.NewFloat(2)) >= 0
+	}
+	return v.Cmp(big.NewFloat(1)) != 0
+}
+
+// name builds the long name for the prefix index, or -1 for none, with per
+// naming the time unit of a rate
+func (n *Names) name(prefix int, isByte bool, per string, plural bool) string {
+	var s string
+	switch {
+	case isByte && plural:
+		s = n.Bytes
+	case isByte:
+		s = n.Byte
+	case plural:
+		s = n.Bits
+	default:
+		s = n.Bit
+	}
+	if prefix >= 0 {
+		s = n.Prefixes[prefix] + s
+	}
+	switch {
+	case n.Lower:
+		s = strings.ToLower(s)
+	case n.Capitalize:
+		r, size := utf8.DecodeRuneInString(s)
+		s = string(unicode.ToUpper(r)) + s[size:]
+	}
+	switch {
+	case per == "s" && n.PerSecond != "":
+		s += n.PerSecond
+	case per != "":
+		s += "/" + per
+	}
+	return s
+}
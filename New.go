@@ -2,40 +2,55 @@ package bunit
 
 import (
 	"time"
-
-	"github.com/cymertek/go-big"
 )
 
 func NewBytes(n int64) *Bytes {
-	v := Bytes(big.NewInt(n).Bytes())
+	v := Bytes{intNum(n)}
 	return &v
 }
 func NewBytesFromSlice(p []byte) *Bytes {
-	v := Bytes(p)
+	v := Bytes{unsigned(p)}
 	return &v
 }
 func NewBits(n int64) *Bits {
-	v := Bits(big.NewInt(n).Bytes())
+	v := Bits{intNum(n)}
 	return &v
 }
 func NewBitsFromSlice(p []byte) *Bits {
-	v := Bits(p)
+	v := Bits{unsigned(p)}
 	return &v
 }
 
 func NewByteRate(n int64, d time.Duration) *ByteRate {
-	v := ByteRate{big.NewInt(n).Bytes(), d}
+	v := ByteRate{Bytes{intNum(n)}, d}
 	return &v
 }
 func NewByteRateFromSlice(p []byte, d time.Duration) *ByteRate {
-	v := ByteRate{p, d}
+	v := ByteRate{Bytes{unsigned(p)}, d}
 	return &v
 }
 func NewBitRate(n int64, d time.Duration) *BitRate {
-	v := BitRate{big.NewInt(n).Bytes(), d}
+	v := BitRate{Bits{intNum(n)}, d}
 	return &v
 }
 func NewBitRateFromSlice(p []byte, d time.Duration) *BitRate {
-	v := BitRate{p, d}
+	v := BitRate{Bits{unsigned(p)}, d}
+	return &v
+}
+func NewByteRateFromBytes(b Bytes, d time.Duration) *ByteRate {
+	v := b.Per(d)
 	return &v
 }
+func NewBitRateFromBits(b Bits, d time.Duration) *BitRate {
+	v := b.Per(d)
+	return &v
+}
+
+// intNum gives the signed value n
+func intNum(n int64) num {
+	u := uint64(n)
+	if n < 0 {
+		u = -u
+	}
+	return num{mag: fromUint64(u), neg: n < 0}
+}
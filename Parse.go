@@ -15,8 +15,8 @@
 package bunit
 
 import (
-	"errors"
 	"log"
+	"strings"
 
 	"github.com/cymertek/go-big"
 )
@@ -30,15 +30,38 @@ func MustParseBytes(s string) Bytes {
 	return b
 }
 
-// Parse a string into a Bytes value
+// Parse a string into a Bytes value, any trailing partial byte is dropped
 func ParseBytes(s string) (Bytes, error) {
-	b, err := ParseBits(s)
-	if err == nil {
-		i := (&big.Int{}).SetBytes(b)
-		i.Rsh(i, 3)
-		return Bytes(i.Bytes()), nil
+	return DefaultParser.ParseBytes(s)
+}
+
+// Parse a string into a Bytes value, any trailing partial byte is dropped
+func (p *Parser) ParseBytes(s string) (Bytes, error) {
+	b, err := p.ParseBits(s)
+	if err != nil {
+		return Bytes{}, err
+	}
+	return b.ToBytes(RoundFloor)
+}
+
+// Like ParseBytes but will return an error if the value does not land on a
+// whole byte, such as "1k203b"
+func ParseBytesStrict(s string) (Bytes, error) {
+	return DefaultParser.ParseBytesStrict(s)
+}
+
+// Like ParseBytes but will return an error if the value does not land on a
+// whole byte, such as "1k203b"
+func (p *Parser) ParseBytesStrict(s string) (Bytes, error) {
+	d, err := p.parseBits(s)
+	if err != nil {
+		return Bytes{}, err
+	}
+	// Check the exact bits, a fraction of a bit is a remainder too
+	if d.Quo(d, big.NewRat(8, 1)); !d.IsInt() {
+		return Bytes{}, parseError(s, 0, s, ErrRemainder, "value "+quote(s)+" is not a whole number of bytes")
 	}
-	return nil, err
+	return Bytes{bytesInt(d.Num())}, nil
 }
 
 // Like ParseBits but will log.Fatal if not able to parse
@@ -50,93 +73,205 @@ func MustParseBits(s string) Bits {
 	return b
 }
 
-var eight = big.NewFloat(8)
+var eight = big.NewRat(8, 1)
 
 // Parse a string into a Bits value
 func ParseBits(s string) (Bits, error) {
-	d := &big.Float{}
+	return DefaultParser.ParseBits(s)
+}
+
+// Parse a string into a Bits value
+func (p *Parser) ParseBits(s string) (Bits, error) {
+	d, err := p.parseBits(s)
+	if err != nil {
+		return Bits{}, err
+	}
+	return Bits{bytesInt(ratInt(d))}, nil
+}
+
+// parseBits reads s into the exact number of bits, which may hold a fraction
+func (p *Parser) parseBits(s string) (*big.Rat, error) {
 	orig := s
+	neg := false
+	if p.Locale != nil {
+		s = p.Locale.normalize(s)
+	}
+	if p.AnySpace {
+		s = strings.TrimLeft(s, " ")
+	}
 
 	// Consume [-+]?
 	if s != "" {
-		switch s[0] {
-		case '-':
-			return nil, errors.New("binary unit: invalid value " + quote(orig))
-		case '+':
+		c := s[0]
+		if c == '-' || c == '+' {
+			if p.NoSign {
+				return nil, parseError(orig, len(orig)-len(s), s[:1], ErrSyntax, "invalid value "+quote(orig))
+			}
+			if c == '-' && p.NoNegative {
+				return nil, parseError(orig, len(orig)-len(s), "-", ErrNegative, "negative value "+quote(orig))
+			}
+			neg = c == '-'
 			s = s[1:]
 		}
 	}
 	// Special case: if all that is left is "0", this is zero.
 	if s == "0" {
-		return []byte{0}, nil
+		return &big.Rat{}, nil
 	}
 	if s == "" {
-		return nil, errors.New("binary unit: invalid value " + quote(orig))
+		return nil, parseError(orig, len(orig), "", ErrBadNumber, "invalid value "+quote(orig))
+	}
+	d, s, err := p.parseTerms(s, orig, false)
+	if err != nil {
+		return nil, err
+	}
+	if neg {
+		d.Neg(d)
 	}
-	for s != "" {
-		var v *big.Float
-		var err error
+	if p.Max.mag != nil && cmpBytes(bytesInt(ratInt(d)), p.Max.num) > 0 {
+		return nil, parseError(orig, 0, orig, ErrOverflow, "value "+quote(orig)+" is over the maximum")
+	}
+	return d, nil
+}
+
+// parseTerms consumes the number and unit terms from s, summing them into an
+// exact number of bits.  With rate the terms stop at a '/' which is left in
+// rem.
+func (p *Parser) parseTerms(s, orig string, rate bool) (d *big.Rat, rem string, err error) {
+	d = &big.Rat{}
+	pending := &big.Rat{} // terms with only a prefix so far
+	terms, open := 0, false
+	shift := 0 // difference in length of an alias and what it replaced
+	pos := func() int { return len(orig) - len(s) + shift }
+	for s != "" && !(rate && s[0] == '/') {
+		if p.AnySpace {
+			if s = strings.TrimLeft(s, " "); s == "" || rate && s[0] == '/' {
+				break
+			}
+		}
 
-		// The next character must be [0-9.]
+		// The next character must be [0-9.], the number may carry an exponent,
+		// '_' digit separators, or be a 0x hexadecimal integer
 		if !(s[0] == '.' || '0' <= s[0] && s[0] <= '9') {
-			return nil, errors.New("binary unit: invalid value " + quote(orig))
+			return nil, "", parseError(orig, pos(), s[:1], ErrBadNumber, "invalid value "+quote(orig))
+		}
+		if terms++; terms > 1 && p.SingleTerm {
+			return nil, "", parseError(orig, pos(), s, ErrSyntax, "more than one term in value "+quote(orig))
+		}
+		// Consume the number
+		v, rest, err := leadingNumber(s)
+		if err == errExponent {
+			return nil, "", parseError(orig, pos()+len(s)-len(rest), exponentText(rest), ErrOverflow, "exponent too large in value "+quote(orig))
 		}
-		// Consume [0-9.]*
-		v, s, err = leadingBigFloat(s)
 		if err != nil {
-			return nil, errors.New("binary unit: invalid value " + quote(orig))
+			return nil, "", parseError(orig, pos(), s[:1], ErrBadNumber, "invalid value "+quote(orig))
 		}
+		s = rest
 
 		// Get rid of spaces
+		if len(s) > 0 && s[0] == ' ' && p.NoSpace {
+			return nil, "", parseError(orig, pos(), " ", ErrSyntax, "space before unit in value "+quote(orig))
+		}
 		for len(s) > 0 && s[0] == ' ' {
 			s = s[1:]
 		}
 
 		// Consume unit.
-		i, b := 0, -1
-		for ; i < len(s); i++ {
-			c := s[i]
-			if c == 'b' || c == 'B' {
-				b = i
-			}
-			if c == '.' || '0' <= c && c <= '9' {
-				break
-			}
+		i, b := p.scanUnit(s, rate)
+		if alias, ok := p.Aliases[s[:i]]; ok && i > 0 {
+			shift = len(alias) - i
+			s = alias + s[i:]
+			i, b = p.scanUnit(s, rate)
 		}
 		if i == 0 {
-			return nil, errors.New("binary unit: missing unit in value " + quote(orig))
+			if p.DefaultUnit == "" {
+				return nil, "", parseError(orig, pos(), "", ErrMissingUnit, "missing unit in value "+quote(orig))
+			}
+			pending.Add(pending, v)
+			open = true
+			continue
 		}
 
 		// Test for the case that we only have the SI suffix
-		if unit, ok := unitMap[s[:i]]; ok {
-			v.Mul(v, big.NewFloat(unit))
-			d.Add(d, v)
-			s = s[i:]
+		if unit, ok := p.unit(s[:i]); ok {
+			v.Mul(v, exactUnit(unit))
+			pending.Add(pending, v)
+			open = true
+			s, shift = s[i:], 0
 			continue // Look for more SI prefixes
 		}
 
+		// Look for a unit named by the locale, or an octet
+		if p.Locale != nil {
+			if k := p.Locale.baseIndex(s[:i], p.IgnoreCase); k >= 0 {
+				b = k
+			}
+		}
+		if b < 0 && s[i-1] == 'o' {
+			b = i - 1
+		}
+
 		if b < 0 {
-			return nil, errors.New("binary unit: missing base unit in value " + quote(orig))
+			if rate {
+				return nil, "", parseError(orig, pos(), s[:i], ErrMissingUnit, "missing rate unit in value "+quote(orig))
+			}
+			return nil, "", parseError(orig, pos(), s[:i], ErrMissingUnit, "missing base unit in value "+quote(orig))
 		}
 
 		// Test for the case that we have the SI suffix and unit
 		u := s[:b]
-		unit, ok := unitMap[u]
+		unit, ok := p.unit(u)
 		if !ok {
-			return nil, errors.New("binary unit: unknown unit " + quote(u) + " in value " + quote(orig))
+			return nil, "", parseError(orig, pos(), u, ErrUnknownUnit, "unknown unit "+quote(u)+" in value "+quote(orig))
+		}
+		v.Mul(v, exactUnit(unit))
+		pending.Add(pending, v)
+
+		// Find a 'p' instead of a slash
+		if rate && len(s) > b+2 && (s[b+1] == 'p' || p.IgnoreCase && s[b+1] == 'P') {
+			s = s[:b+1] + "/" + s[b+2:]
+			i = b + 1
+		}
+
+		isByte, ok := p.base(s[b:i])
+		if !ok {
+			return nil, "", parseError(orig, pos()+b, s[b:i], ErrUnknownUnit, "missing byte or bit unit in value "+quote(orig))
+		}
+		if isByte {
+			pending.Mul(pending, eight)
 		}
-		v.Mul(v, big.NewFloat(unit))
-		d.Add(d, v)
+		d.Add(d, pending)
+		pending, open = &big.Rat{}, false
+		s, shift = s[i:], 0
+	}
+
+	// Terms without a base unit take the default unit, or bits when not set
+	if open {
+		if p.DefaultUnit != "" {
+			isByte, ok := p.base(p.DefaultUnit)
+			if !ok {
+				return nil, "", parseError(orig, len(orig), p.DefaultUnit, ErrUnknownUnit, "invalid default unit "+quote(p.DefaultUnit))
+			}
+			if isByte {
+				pending.Mul(pending, eight)
+			}
+		}
+		d.Add(d, pending)
+	}
+	return d, s, nil
+}
 
-		switch s[b:i] {
-		case "b", "bit", "Bit", "bits", "Bits":
-		case "o", "B", "byte", "Byte", "bytes", "Bytes":
-			d.Mul(d, eight)
-		default:
-			return nil, errors.New("binary unit: missing byte or bit unit in value " + quote(orig))
+// scanUnit finds the end of the unit at the start of s and the position of
+// the last 'b' or 'B' in it
+func (p *Parser) scanUnit(s string, rate bool) (i, b int) {
+	b = -1
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == 'b' || c == 'B' {
+			b = i
+		} else if c == '.' || c == ' ' || '0' <= c && c <= '9' || rate && c == '/' {
+			break
 		}
-		s = s[i:] // This should be the end
 	}
-	b, _ := d.Bytes()
-	return Bits(b), nil
+	return
 }
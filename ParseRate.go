@@ -15,7 +15,7 @@
 package bunit
 
 import (
-	"errors"
+	"strings"
 	"time"
 
 	"github.com/cymertek/go-big"
@@ -23,126 +23,109 @@ import (
 
 // Parse a string into a ByteRate value
 func ParseByteRate(s string) (*ByteRate, error) {
-	r, err := ParseBitRate(s)
-	// Scale up the demonitor (time) for Bytes
-	return &ByteRate{Bytes(r.n), r.d << 3}, err
+	return DefaultParser.ParseByteRate(s)
+}
+
+// Parse a string into a ByteRate value
+func (p *Parser) ParseByteRate(s string) (*ByteRate, error) {
+	r, err := p.ParseBitRate(s)
+	if err != nil {
+		return nil, err
+	}
+	// Whole bytes keep the duration, otherwise it is lengthened, which may
+	// not fit for a long duration such as "1b/40y"
+	b := r.ToByteRate()
+	if x := b.Rat(); x.Mul(x, big.NewRat(8, 1)).Cmp(r.Rat()) != 0 {
+		i := strings.LastIndexByte(s, '/') + 1
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		return nil, parseError(s, i, strings.TrimRight(s[i:], " "), ErrBadDuration, "time too long for a byte rate in value "+quote(s))
+	}
+	return &b, nil
 }
 
 // Parse a string into a BitRate value
 func ParseBitRate(s string) (*BitRate, error) {
+	return DefaultParser.ParseBitRate(s)
+}
+
+// Parse a string into a BitRate value
+func (p *Parser) ParseBitRate(s string) (*BitRate, error) {
 	orig := s
-	d := &big.Float{}
 	neg := false
+	if p.Locale != nil {
+		s = p.Locale.normalize(s)
+	}
+	if p.AnySpace {
+		s = strings.TrimLeft(s, " ")
+	}
 
 	// Consume [-+]?
 	if s != "" {
 		c := s[0]
 		if c == '-' || c == '+' {
+			if p.NoSign {
+				return nil, parseError(orig, len(orig)-len(s), s[:1], ErrSyntax, "invalid value "+quote(orig))
+			}
+			if c == '-' && p.NoNegative {
+				return nil, parseError(orig, len(orig)-len(s), "-", ErrNegative, "negative value "+quote(orig))
+			}
 			neg = c == '-'
 			s = s[1:]
 		}
 	}
 	// Special case: if all that is left is "0", this is zero.
 	if s == "0" {
-		return nil, nil
+		return &BitRate{Bits{}, time.Second}, nil
 	}
 	if s == "" {
-		return nil, errors.New("binary unit: invalid value " + quote(orig))
+		return nil, parseError(orig, len(orig), "", ErrBadNumber, "invalid value "+quote(orig))
 	}
 
-	for s != "" && s[0] != '/' {
-		var v *big.Float
-		var err error
-
-		// The next character must be [0-9.]
-		if !(s[0] == '.' || '0' <= s[0] && s[0] <= '9') {
-			return nil, errors.New("binary unit: invalid value " + quote(orig))
-		}
-
-		// Consume [0-9.]*
-		v, s, err = leadingBigFloat(s)
-		if err != nil {
-			return nil, errors.New("binary unit: invalid value " + quote(orig))
-		}
-
-		// Get rid of spaces
-		for len(s) > 0 && s[0] == ' ' {
-			s = s[1:]
-		}
-
-		// Consume unit.
-		i, b := 0, -1
-		for ; i < len(s); i++ {
-			c := s[i]
-			if c == 'b' || c == 'B' {
-				b = i
-			} else if c == '.' || '0' <= c && c <= '9' || c == '/' {
-				break
-			}
-		}
-		if i == 0 {
-			return nil, errors.New("binary unit: missing unit in value " + quote(orig))
-		}
-
-		// Test for the case that we only have the SI suffix
-		if unit, ok := unitMap[s[:i]]; ok {
-			v.Mul(v, big.NewFloat(unit))
-			d.Add(d, v)
-			s = s[i:]
-			continue // Look for more SI prefixes
-		}
-
-		if b < 0 {
-			return nil, errors.New("binary unit: missing rate unit in value " + quote(orig))
-		}
-
-		// Test for the case that we have the SI suffix and unit
-		u := s[:b]
-		unit, ok := unitMap[u]
-		if !ok {
-			return nil, errors.New("binary unit: unknown unit " + quote(u) + " in value " + quote(orig))
-		}
-		v.Mul(v, big.NewFloat(unit))
-		d.Add(d, v)
-
-		// Find a 'p' instead of a slash
-		if len(s) > b+2 && s[b+1] == 'p' {
-			s = s[:b+1] + "/" + s[b+2:]
-			i = b + 1
-		}
-
-		switch s[b:i] {
-		case "bit", "b", "Bit", "bits", "Bits":
-		case "byte", "B", "Byte", "bytes", "Bytes":
-			v.Mul(v, eight)
-		default:
-			return nil, errors.New("binary unit: missing byte or bit unit in value " + quote(orig))
-		}
-		s = s[i:]
+	d, s, err := p.parseTerms(s, orig, true)
+	if err != nil {
+		return nil, err
+	}
+	if p.AnySpace {
+		s = strings.TrimLeft(s, " ")
 	}
 
 	if s == "" || s[0] != '/' || s == "/" {
-		return nil, errors.New("binary unit: missing time in value " + quote(orig))
+		return nil, parseError(orig, len(orig)-len(s), s, ErrBadDuration, "missing time in value "+quote(orig))
 	}
 	s = s[1:]
+	if p.AnySpace {
+		// The offset is taken before the trailing spaces are dropped
+		s = strings.TrimLeft(s, " ")
+	}
+	at := len(orig) - len(s)
+	if p.AnySpace {
+		s = strings.TrimRight(s, " ")
+	}
+	if p.IgnoreCase {
+		s = strings.ToLower(s)
+	}
 
 	// Consume the duration
-	var t time.Duration
-	if s == "s" { // Do the simple stuff first
-		t = time.Second
-	} else {
-		if c := s[0]; c < '0' || c > '9' {
-			s = "1" + s
-		}
-		var err error
-		t, err = time.ParseDuration(s)
-		if err != nil {
-			return nil, errors.New("binary unit: error parsing time in value " + quote(orig))
-		}
+	if s == "" {
+		return nil, parseError(orig, at, "", ErrBadDuration, "missing time in value "+quote(orig))
+	}
+	t, err := parseRateDuration(s)
+	if err != nil {
+		return nil, parseError(orig, at, orig[at:at+len(s)], ErrBadDuration, "error parsing time in value "+quote(orig))
 	}
 	if neg {
-		t = -t
+		d.Neg(d)
+	}
+	// A fraction of a bit moves into the duration, "1.5b/s" is 3b over 2s
+	r := d.Quo(d, big.NewRat(int64(t), 1))
+	b, t := rateOf(r, t)
+	if ratePerNano(b, t).Cmp(r) != 0 {
+		return nil, parseError(orig, at, orig[at:at+len(s)], ErrBadDuration, "time too long for the fraction in value "+quote(orig))
+	}
+	if p.MaxRate != nil && cmpRate(b, t, p.MaxRate.n.num, p.MaxRate.d) > 0 {
+		return nil, parseError(orig, 0, orig, ErrOverflow, "value "+quote(orig)+" is over the maximum")
 	}
-	b, _ := d.Bytes()
-	return &BitRate{b, t}, nil
+	return &BitRate{Bits{b}, t}, nil
 }
@@ -0,0 +1,98 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"strings"
+)
+
+// Convention selects how prefixes without an "i" are read
+type Convention int
+
+const (
+	ConventionSI    Convention = iota // k, K and M are powers of 1000 while Ki and Mi are powers of 1024
+	ConventionIEC                     // Like ConventionSI but the non-standard "K" is rejected
+	ConventionJEDEC                   // K, M and G are powers of 1024, as in RAM specs, Windows and Java
+)
+
+// Parser holds the settings for reading values, the zero Parser reads values
+// like the package level Parse functions.
+type Parser struct {
+	Convention Convention
+
+	SingleTerm  bool              // Reject compound values such as "1k200b"
+	NoSpace     bool              // Reject a space between the number and the unit
+	AnySpace    bool              // Allow spaces around the value, between terms and around the '/'
+	NoSign      bool              // Reject a leading '+' or '-'
+	NoNegative  bool              // Reject negative values such as "-3GiB"
+	IgnoreCase  bool              // Match prefixes and spelled out units without regard to case, "b" and "B" keep their meaning
+	DefaultUnit string            // Unit, "b" or "B", used for values given without one such as "1024" or "4k", bits when empty
+	Aliases     map[string]string // Extra spellings of a unit mapped to a known one, like "octets": "B" or "Go": "GB"
+	Max         Bits              // Largest size accepted when set
+	MaxRate     *BitRate          // Largest rate accepted when set
+	Locale      *Locale           // Separators and unit names to accept, like "1 234,5 Mo"
+}
+
+// DefaultParser is used by the package level Parse functions
+var DefaultParser = Parser{}
+
+// unit looks up the multiplier for a prefix under the parser's convention
+func (p *Parser) unit(u string) (float64, bool) {
+	switch p.Convention {
+	case ConventionIEC:
+		if u == "K" {
+			return 0, false
+		}
+	case ConventionJEDEC:
+		if unit, ok := jedecMap[u]; ok {
+			return unit, true
+		}
+		if unit, ok := jedecMap[strings.ToLower(u)]; ok && p.IgnoreCase {
+			return unit, true
+		}
+	}
+	if unit, ok := unitMap[u]; ok {
+		return unit, true
+	}
+	if p.IgnoreCase {
+		unit, ok := foldMap[strings.ToLower(u)]
+		return unit, ok
+	}
+	return 0, false
+}
+
+// base reports if u is a byte or bit unit
+func (p *Parser) base(u string) (isByte bool, ok bool) {
+	if p.Locale != nil {
+		if isByte, ok = p.Locale.base(u, p.IgnoreCase); ok {
+			return
+		}
+	}
+	switch u {
+	case "b", "bit", "Bit", "bits", "Bits":
+		return false, true
+	case "o", "B", "byte", "Byte", "bytes", "Bytes":
+		return true, true
+	}
+	if p.IgnoreCase && len(u) > 1 {
+		switch strings.ToLower(u) {
+		case "bit", "bits":
+			return false, true
+		case "byte", "bytes":
+			return true, true
+		}
+	}
+	return false, false
+}
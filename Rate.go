@@ -0,0 +1,118 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"time"
+
+	"github.com/cymertek/go-big"
+)
+
+const maxDuration = time.Duration(1<<63 - 1)
+
+// Per gives the rate of moving b in the duration d
+func (b Bytes) Per(d time.Duration) ByteRate {
+	return ByteRate{Bytes{copyBytes(b.num)}, d}
+}
+
+// Per gives the rate of moving b in the duration d
+func (b Bits) Per(d time.Duration) BitRate {
+	return BitRate{Bits{copyBytes(b.num)}, d}
+}
+
+// Amount gives the bytes moved in each Duration of the rate, as it was written
+// such as the 10MB of "10MB/100ms"
+func (b ByteRate) Amount() Bytes {
+	return Bytes{copyBytes(b.n.num)}
+}
+
+// Amount gives the bits moved in each Duration of the rate, as it was written
+// such as the 10Mb of "10Mb/100ms"
+func (b BitRate) Amount() Bits {
+	return Bits{copyBytes(b.n.num)}
+}
+
+// Duration gives the time window the Amount of the rate is moved in
+func (b ByteRate) Duration() time.Duration { return b.d }
+
+// Duration gives the time window the Amount of the rate is moved in
+func (b BitRate) Duration() time.Duration { return b.d }
+
+// PerSecond gives the number of whole bytes moved each second, any partial
+// byte is dropped
+func (b ByteRate) PerSecond() Bytes {
+	return b.Over(time.Second)
+}
+
+// PerSecond gives the number of whole bits moved each second, any partial bit
+// is dropped
+func (b BitRate) PerSecond() Bits {
+	return b.Over(time.Second)
+}
+
+// Over gives the number of whole bytes moved at the rate b over the duration
+// d, any partial byte is dropped
+func (b ByteRate) Over(d time.Duration) Bytes {
+	return Bytes{overRate(b.n.num, b.d, d)}
+}
+
+// Over gives the number of whole bits moved at the rate b over the duration
+// d, any partial bit is dropped
+func (b BitRate) Over(d time.Duration) Bits {
+	return Bits{overRate(b.n.num, b.d, d)}
+}
+
+// TimeAt gives the time needed to move b at the rate r, rounded up to the
+// next nanosecond.  A zero rate, a rate against the sign of b, or a time too
+// long to fit gives the maximum duration.
+func (b Bytes) TimeAt(r ByteRate) time.Duration {
+	return timeAt(b.num, r.n.num, r.d)
+}
+
+// TimeAt gives the time needed to move b at the rate r, rounded up to the
+// next nanosecond.  A zero rate, a rate against the sign of b, or a time too
+// long to fit gives the maximum duration.
+func (b Bits) TimeAt(r BitRate) time.Duration {
+	return timeAt(b.num, r.n.num, r.d)
+}
+
+func overRate(n num, d, over time.Duration) num {
+	if d <= 0 || over <= 0 {
+		return num{}
+	}
+	i := intBytes(n)
+	i.Mul(i, big.NewInt(int64(over)))
+	return bytesInt(i.Quo(i, big.NewInt(int64(d))))
+}
+
+func timeAt(size, n num, d time.Duration) time.Duration {
+	num, t := intBytes(n), intBytes(size)
+	if num.Sign() == 0 || d <= 0 || num.Sign()*t.Sign() < 0 {
+		return maxDuration
+	}
+	if num.Sign() < 0 {
+		num.Neg(num)
+		t.Neg(t)
+	}
+	// size * d / n rounded up
+	t.Mul(t, big.NewInt(int64(d)))
+	t.Add(t, num)
+	t.Sub(t, big.NewInt(1))
+	t.Quo(t, num)
+	if !t.IsInt64() {
+		return maxDuration
+	}
+	return time.Duration(t.Int64())
+}
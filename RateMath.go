@@ -0,0 +1,147 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"time"
+
+	"github.com/cymertek/go-big"
+)
+
+// Add returns the sum b + o, which is exact even when the durations differ
+func (b ByteRate) Add(o ByteRate) ByteRate {
+	n, d := addRate(b.n.num, b.d, o.n.num, o.d, 1)
+	return ByteRate{Bytes{n}, d}
+}
+
+// Add returns the sum b + o, which is exact even when the durations differ
+func (b BitRate) Add(o BitRate) BitRate {
+	n, d := addRate(b.n.num, b.d, o.n.num, o.d, 1)
+	return BitRate{Bits{n}, d}
+}
+
+// Sub returns the difference b - o, such as the headroom left under a cap
+func (b ByteRate) Sub(o ByteRate) ByteRate {
+	n, d := addRate(b.n.num, b.d, o.n.num, o.d, -1)
+	return ByteRate{Bytes{n}, d}
+}
+
+// Sub returns the difference b - o, such as the headroom left under a cap
+func (b BitRate) Sub(o BitRate) BitRate {
+	n, d := addRate(b.n.num, b.d, o.n.num, o.d, -1)
+	return BitRate{Bits{n}, d}
+}
+
+// Mul returns the rate b * n
+func (b ByteRate) Mul(n int64) ByteRate { return ByteRate{Bytes{mulBytes(b.n.num, n)}, b.d} }
+
+// Mul returns the rate b * n
+func (b BitRate) Mul(n int64) BitRate { return BitRate{Bits{mulBytes(b.n.num, n)}, b.d} }
+
+// Quo returns the rate b / n.  Quo panics if n is zero.
+func (b ByteRate) Quo(n int64) ByteRate {
+	r, d := quoRate(b.n.num, b.d, n)
+	return ByteRate{Bytes{r}, d}
+}
+
+// Quo returns the rate b / n.  Quo panics if n is zero.
+func (b BitRate) Quo(n int64) BitRate {
+	r, d := quoRate(b.n.num, b.d, n)
+	return BitRate{Bits{r}, d}
+}
+
+// Cmp compares b and o and returns -1 if b < o, 0 if b == o, +1 if b > o
+func (b ByteRate) Cmp(o ByteRate) int {
+	return ratePerNano(b.n.num, b.d).Cmp(ratePerNano(o.n.num, o.d))
+}
+
+// Cmp compares b and o and returns -1 if b < o, 0 if b == o, +1 if b > o
+func (b BitRate) Cmp(o BitRate) int { return ratePerNano(b.n.num, b.d).Cmp(ratePerNano(o.n.num, o.d)) }
+
+// Sign returns -1 if b is negative, 0 if b is zero and +1 if b is positive
+func (b ByteRate) Sign() int { return ratePerNano(b.n.num, b.d).Sign() }
+
+// Sign returns -1 if b is negative, 0 if b is zero and +1 if b is positive
+func (b BitRate) Sign() int { return ratePerNano(b.n.num, b.d).Sign() }
+
+// Normalize gives the rate over the duration to, such as time.Second, any
+// partial byte is dropped
+func (b ByteRate) Normalize(to time.Duration) ByteRate {
+	return ByteRate{Bytes{overRate(b.n.num, b.d, to)}, to}
+}
+
+// Normalize gives the rate over the duration to, such as time.Second, any
+// partial bit is dropped
+func (b BitRate) Normalize(to time.Duration) BitRate {
+	return BitRate{Bits{overRate(b.n.num, b.d, to)}, to}
+}
+
+// ToBitRate converts the rate into the exact bit rate
+func (b ByteRate) ToBitRate() BitRate {
+	return BitRate{Bits{mulBytes(b.n.num, 8)}, b.d}
+}
+
+// ToByteRate converts the rate into the exact byte rate, the duration is
+// lengthened when the bits do not land on whole bytes
+func (b BitRate) ToByteRate() ByteRate {
+	n, d := quoRate(b.n.num, b.d, 8)
+	return ByteRate{Bytes{n}, d}
+}
+
+// ratePerNano gives the exact rate of n over d per nanosecond, an unset
+// duration is a zero rate
+func ratePerNano(n num, d time.Duration) *big.Rat {
+	if d == 0 {
+		return &big.Rat{}
+	}
+	return (&big.Rat{}).SetFrac(intBytes(n), big.NewInt(int64(d)))
+}
+
+func addRate(n1 num, d1 time.Duration, n2 num, d2 time.Duration, sign int64) (num, time.Duration) {
+	r := ratePerNano(n2, d2)
+	r.Mul(r, big.NewRat(sign, 1))
+	return rateOf(r.Add(r, ratePerNano(n1, d1)), d1, d2, time.Second)
+}
+
+func quoRate(n num, d time.Duration, k int64) (num, time.Duration) {
+	r := ratePerNano(n, d)
+	r.Quo(r, big.NewRat(k, 1))
+	if k < 0 {
+		k = -k
+	}
+	if dk := d * time.Duration(k); d > 0 && dk/time.Duration(k) == d {
+		// The same amount over a longer time
+		return rateOf(r, d, dk)
+	}
+	return rateOf(r, d, time.Second)
+}
+
+// rateOf turns a rate per nanosecond into an amount and duration.  The first
+// of the preferred durations holding a whole amount is used, then the
+// smallest exact duration, and when no duration is exact the amount is
+// rounded to a billionth of a unit per second.
+func rateOf(r *big.Rat, prefer ...time.Duration) (num, time.Duration) {
+	for _, d := range prefer {
+		if d > 0 {
+			if n := (&big.Rat{}).Mul(r, big.NewRat(int64(d), 1)); n.IsInt() {
+				return bytesInt(n.Num()), d
+			}
+		}
+	}
+	if r.Denom().IsInt64() {
+		return bytesInt(r.Num()), time.Duration(r.Denom().Int64())
+	}
+	return rateFromRat(r.Mul(r, big.NewRat(int64(time.Second), 1)))
+}
@@ -0,0 +1,197 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cymertek/go-big"
+)
+
+// SQLMode selects how values are written by Value
+type SQLMode int
+
+const (
+	SQLNumber  SQLMode = iota // Write an int64 when the value fits, otherwise a decimal string
+	SQLDecimal                // Always write a decimal string, for NUMERIC columns
+	SQLText                   // Write the MarshalText form, like "512MiB"
+)
+
+// ValueSQLMode controls the output of Value for all the unit types.  Rates are
+// written as the number per second, a rate which does not work out to a whole
+// number per second is written in the SQLText form.
+var ValueSQLMode = SQLNumber
+
+// Value implements the driver.Valuer interface
+func (b Bytes) Value() (driver.Value, error) {
+	if ValueSQLMode == SQLText {
+		txt, _ := b.MarshalText()
+		return string(txt), nil
+	}
+	return sqlNumber(intBytes(b.num)), nil
+}
+
+// Scan implements the sql.Scanner interface.  Integers, whole numeric text of
+// any length, and strings accepted by ParseBytes are understood.
+func (b *Bytes) Scan(src interface{}) error {
+	txt, num, err := scanSQL(src)
+	switch {
+	case err != nil:
+		return err
+	case num != nil:
+		n, err := wholeNumber(num, txt, "bytes")
+		if err != nil {
+			return err
+		}
+		*b = Bytes{n}
+	case txt != "":
+		return b.UnmarshalText([]byte(txt))
+	default:
+		*b = Bytes{}
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (b Bits) Value() (driver.Value, error) {
+	if ValueSQLMode == SQLText {
+		txt, _ := b.MarshalText()
+		return string(txt), nil
+	}
+	return sqlNumber(intBytes(b.num)), nil
+}
+
+// Scan implements the sql.Scanner interface.  Integers, whole numeric text of
+// any length, and strings accepted by ParseBits are understood.
+func (b *Bits) Scan(src interface{}) error {
+	txt, num, err := scanSQL(src)
+	switch {
+	case err != nil:
+		return err
+	case num != nil:
+		n, err := wholeNumber(num, txt, "bits")
+		if err != nil {
+			return err
+		}
+		*b = Bits{n}
+	case txt != "":
+		return b.UnmarshalText([]byte(txt))
+	default:
+		*b = Bits{}
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (b ByteRate) Value() (driver.Value, error) {
+	if r := ratePerSecond(b.n.num, b.d); ValueSQLMode != SQLText && r.IsInt() {
+		return sqlNumber(r.Num()), nil
+	}
+	txt, _ := b.MarshalText()
+	return string(txt), nil
+}
+
+// Scan implements the sql.Scanner interface.  Integers and numeric text are
+// taken as bytes per second, otherwise strings accepted by ParseByteRate are
+// understood.
+func (b *ByteRate) Scan(src interface{}) error {
+	txt, num, err := scanSQL(src)
+	switch {
+	case err != nil:
+		return err
+	case num != nil:
+		n, d := rateFromRat(num)
+		*b = ByteRate{Bytes{n}, d}
+	case txt != "":
+		return b.UnmarshalText([]byte(txt))
+	default:
+		*b = ByteRate{}
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (b BitRate) Value() (driver.Value, error) {
+	if r := ratePerSecond(b.n.num, b.d); ValueSQLMode != SQLText && r.IsInt() {
+		return sqlNumber(r.Num()), nil
+	}
+	txt, _ := b.MarshalText()
+	return string(txt), nil
+}
+
+// Scan implements the sql.Scanner interface.  Integers and numeric text are
+// taken as bits per second, otherwise strings accepted by ParseBitRate are
+// understood.
+func (b *BitRate) Scan(src interface{}) error {
+	txt, num, err := scanSQL(src)
+	switch {
+	case err != nil:
+		return err
+	case num != nil:
+		n, d := rateFromRat(num)
+		*b = BitRate{Bits{n}, d}
+	case txt != "":
+		return b.UnmarshalText([]byte(txt))
+	default:
+		*b = BitRate{}
+	}
+	return nil
+}
+
+// sqlNumber gives an int64 when allowed and the value fits, otherwise the
+// decimal string
+func sqlNumber(i *big.Int) driver.Value {
+	if ValueSQLMode == SQLNumber && i.IsInt64() {
+		return i.Int64()
+	}
+	return i.String()
+}
+
+// scanSQL splits a database value into either a string to be parsed or an
+// exact number along with its text, a NULL gives neither.
+func scanSQL(src interface{}) (txt string, num *big.Rat, err error) {
+	switch v := src.(type) {
+	case nil:
+		return "", nil, nil
+	case int64:
+		return strconv.FormatInt(v, 10), big.NewRat(v, 1), nil
+	case float64:
+		if num = (&big.Rat{}).SetFloat64(v); num == nil {
+			return "", nil, fmt.Errorf("binary unit: invalid value %g", v)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), num, nil
+	case []byte:
+		txt = string(v)
+	case string:
+		txt = v
+	default:
+		return "", nil, fmt.Errorf("binary unit: cannot scan type %T", src)
+	}
+	txt = strings.TrimSpace(txt)
+	if txt == "" {
+		return "", nil, errors.New("binary unit: invalid value " + quote(txt))
+	}
+	// Numeric text of any length is taken as an exact number
+	if c := strings.TrimPrefix(txt, "-"); c != "" && '0' <= c[0] && c[0] <= '9' && !strings.ContainsAny(txt, "/") {
+		if num, err := exactNumber(txt); err == nil || errors.Is(err, ErrOverflow) {
+			return txt, num, err
+		}
+	}
+	return txt, nil, nil
+}
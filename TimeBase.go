@@ -0,0 +1,160 @@
+// Copyright 2023 github.com/pschou
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bunit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cymertek/go-big"
+)
+
+// Time bases longer than time.Hour for rates.  A month is a twelfth of the
+// average Gregorian year of 365.2425 days.
+const (
+	Day   = 24 * time.Hour
+	Week  = 7 * Day
+	Year  = 31556952 * time.Second
+	Month = Year / 12
+)
+
+// rateUnits are the denominators understood beyond those of
+// time.ParseDuration
+var rateUnits = map[string]time.Duration{
+	"sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": Day, "day": Day, "days": Day,
+	"w": Week, "wk": Week, "week": Week, "weeks": Week,
+	"mo": Month, "month": Month, "months": Month,
+	"y": Year, "yr": Year, "year": Year, "years": Year,
+}
+
+// timeLabel gives the unit written after the '/' of a rate in the time base d,
+// zero or less is per second
+func timeLabel(d time.Duration) string {
+	if d <= 0 {
+		return "s"
+	}
+	switch d {
+	case time.Second:
+		return "s"
+	case time.Minute:
+		return "min"
+	case time.Hour:
+		return "h"
+	case Day:
+		return "d"
+	case Week:
+		return "wk"
+	case Month:
+		return "mo"
+	case Year:
+		return "yr"
+	}
+	return d.String()
+}
+
+// rateSpans are the units, largest first, for writing the time base of a rate
+// as a whole count of one unit
+var rateSpans = []struct {
+	d    time.Duration
+	unit string
+}{
+	{Year, "yr"}, {Month, "mo"}, {Week, "wk"}, {Day, "d"}, {time.Hour, "h"},
+	{time.Minute, "min"}, {time.Second, "s"}, {time.Millisecond, "ms"},
+	{time.Microsecond, "us"}, {time.Nanosecond, "ns"},
+}
+
+// durationText writes the positive duration d as a whole count of the largest
+// unit which divides it, such as "mo", "2d" or "100ms", which parseRateDuration
+// reads back exactly
+func durationText(d time.Duration) string {
+	for _, s := range rateSpans {
+		if d%s.d == 0 {
+			if d == s.d {
+				return s.unit
+			}
+			return strconv.FormatInt(int64(d/s.d), 10) + s.unit
+		}
+	}
+	return d.String()
+}
+
+// parseRateDuration reads the denominator of a rate, such as "s", "day",
+// "2w" or "1h30m"
+func parseRateDuration(s string) (time.Duration, error) {
+	if s == "s" { // Do the simple stuff first
+		return time.Second, nil
+	}
+	dur := s
+	if c := s[0]; c < '0' || c > '9' {
+		dur = "1" + s
+	}
+	if v, rest, err := leadingNumber(dur); err == nil {
+		if unit, ok := rateUnits[rest]; ok {
+			v.Mul(v, big.NewRat(int64(unit), 1))
+			if t := ratInt(v); t.IsInt64() && t.Sign() > 0 {
+				return time.Duration(t.Int64()), nil
+			}
+			return 0, ErrBadDuration
+		}
+	}
+	t, err := time.ParseDuration(dur)
+	if err == nil && t <= 0 {
+		return 0, ErrBadDuration
+	}
+	return t, err
+}
+
+// In gives a fmt.Formatter which writes the rate in the time base d, such as
+// Month for "1.2TiB/mo", with the same verbs.  A d of zero or less is per
+// second.
+func (b ByteRate) In(d time.Duration) fmt.Formatter {
+	return rateIn{b.n.num, b.d, d, 'B'}
+}
+
+// In gives a fmt.Formatter which writes the rate in the time base d, such as
+// Day for "4.5Gb/d", with the same verbs.  A d of zero or less is per second.
+func (b BitRate) In(d time.Duration) fmt.Formatter {
+	return rateIn{b.n.num, b.d, d, 'b'}
+}
+
+type rateIn struct {
+	n       num
+	d, base time.Duration
+	def     rune
+}
+
+// Format for use in Printf
+func (r rateIn) Format(f fmt.State, verb rune) {
+	r.format(f, verb, nil)
+}
+
+func (r rateIn) format(f fmt.State, verb rune, loc *Locale) {
+	scale := rateScale(r.d)
+	if r.base > 0 && r.d != 0 {
+		scale = big.NewRat(int64(r.base), int64(r.d))
+	}
+	suf := string(r.def) + "/" + timeLabel(r.base)
+	if r.base <= 0 || r.base == time.Second {
+		suf = string(r.def) + "/s"
+		if r.def == 'b' {
+			suf = "bps"
+		}
+	}
+	formatByte(r.n, scale, f, verb, r.def, suf, loc)
+}
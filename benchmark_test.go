@@ -0,0 +1,63 @@
+package bunit_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pschou/go-bunit"
+)
+
+func BenchmarkBytes_AppendFormat(b *testing.B) {
+	val := *bunit.NewBytes(1536 << 20)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = val.AppendFormat(buf[:0], 'V')
+	}
+}
+
+func BenchmarkBytes_AppendFormatBig(b *testing.B) {
+	val := bunit.MustParseBytes("1.5QiB")
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = val.AppendFormat(buf[:0], 'V')
+	}
+}
+
+func BenchmarkBits_AppendFormat(b *testing.B) {
+	val := *bunit.NewBits(13529000000)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = val.AppendFormat(buf[:0], 'v')
+	}
+}
+
+func BenchmarkByteRate_AppendFormat(b *testing.B) {
+	val := *bunit.NewByteRate(20e6, time.Second)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = val.AppendFormat(buf[:0], 'v')
+	}
+}
+
+func BenchmarkBitRate_AppendFormat(b *testing.B) {
+	val := *bunit.NewBitRate(1544000, time.Second)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = val.AppendFormat(buf[:0], 'k')
+	}
+}
+
+func BenchmarkBytes_Fprintf(b *testing.B) {
+	val := *bunit.NewBytes(1536 << 20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fmt.Fprintf(io.Discard, "%V", val)
+	}
+}
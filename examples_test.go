@@ -1,7 +1,13 @@
 package bunit_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/pschou/go-bunit"
@@ -160,10 +166,639 @@ func ExampleParseBitRate() {
 	// 1kbps = 1kbps
 	// 1kb/s = 1kbps
 	// 1M400kb/s = 1.4Mbps
-	// 60kB/m = 1kbps
-	// 1MB/s = 1Mbps
-	// 1KiB/s = 1.024kbps
+	// 60kB/m = 8kbps
+	// 1MB/s = 8Mbps
+	// 1KiB/s = 8.192kbps
 	// 1.544 Mbps = 1.544Mbps
 	// 6.312 Mbit/s = 6.312Mbps
 	// 44.736 MBits/s = 44.736Mbps
 }
+
+func ExampleBytes_Add() {
+	a := bunit.MustParseBytes("1.5GiB")
+	b := bunit.MustParseBytes("512MiB")
+
+	fmt.Printf("sum = %V\n", a.Add(b))
+	fmt.Printf("diff = %V\n", a.Sub(b))
+	fmt.Printf("triple = %V\n", b.Mul(3))
+	fmt.Println("cmp =", a.Cmp(b))
+	// Output:
+	// sum = 2GiB
+	// diff = 1GiB
+	// triple = 1.5GiB
+	// cmp = 1
+}
+
+func ExampleBytes_Quo() {
+	// Quo truncates towards zero and Mod takes the sign of the value divided
+	size := bunit.MustParseBytes("-10kB")
+	fmt.Println(size.Quo(3), size.Mod(3))
+	fmt.Println(size.Quo(-3), size.Mod(-3))
+	fmt.Println(size.Abs().Mod(-3))
+
+	bits := *bunit.NewBits(-7)
+	fmt.Println(bits.Quo(2), bits.Mod(2), bits.Abs(), bits.Min(bits.Abs()), bits.Max(bits.Abs()), bits.IsZero())
+
+	// Padding to the next 4KiB block
+	file := bunit.MustParseBytes("10000B")
+	if rem := file.Mod(4096); !rem.IsZero() {
+		fmt.Printf("pad = %d\n", bunit.NewBytes(4096).Sub(rem).Int64())
+	}
+	// Output:
+	// -3.333kB -1B
+	// 3.333kB -1B
+	// 1B
+	// -3b -1b 7b -7b 7b false
+	// pad = 2288
+}
+
+func ExampleBytes_Min() {
+	a, b := bunit.MustParseBytes("-1.5kB"), bunit.MustParseBytes("1kB")
+	fmt.Println(a.Min(b), a.Max(b), a.Abs(), b.Neg())
+	fmt.Println(a.Add(b).IsZero(), a.Add(b).Add(bunit.MustParseBytes("500B")).IsZero())
+
+	// Results never share storage with the values they came from
+	raw := []byte{4, 0}
+	c := bunit.NewBytesFromSlice(raw)
+	lo, hi, abs, sum := c.Min(b), c.Max(b), c.Abs(), c.Add(bunit.Bytes{})
+	raw[0] = 8
+	fmt.Println(c, lo, hi, abs, sum)
+	// Output:
+	// -1.5kB 1kB 1.5kB -1kB
+	// false true
+	// 2.048kB 1kB 1.024kB 1.024kB 1.024kB
+}
+
+func ExampleBits_ToBytes() {
+	val := bunit.MustParseBits("1k203b")
+
+	down, _ := val.ToBytes(bunit.RoundFloor)
+	up, _ := val.ToBytes(bunit.RoundCeil)
+	_, err := val.ToBytes(bunit.RoundExact)
+	fmt.Printf("floor = %B\nceil = %B\nexact = %v\n", down, up, err)
+	fmt.Println(errors.Is(err, bunit.ErrRemainder))
+	// Output:
+	// floor = 150B
+	// ceil = 151B
+	// exact = binary unit: partial byte of 3 bits converting to bytes
+	// true
+}
+
+func ExampleParseBytesStrict() {
+	for _, s := range []string{"1k200b", "1k203b", "1.0625B"} {
+		val, err := bunit.ParseBytesStrict(s)
+		fmt.Println(val, errors.Is(err, bunit.ErrRemainder))
+	}
+	// Output:
+	// 150B false
+	// 0B true
+	// 0B true
+}
+
+func ExampleBytes_MarshalText() {
+	val := bunit.MustParseBytes("1.5GiB")
+	txt, _ := val.MarshalText()
+	fmt.Println(string(txt))
+
+	// Values which cannot be exactly written with a prefix are given in full
+	val = bunit.MustParseBytes("1000000001B")
+	txt, _ = val.MarshalText()
+	fmt.Println(string(txt))
+
+	var back bunit.Bytes
+	back.UnmarshalText(txt)
+	fmt.Println(back.Cmp(val) == 0)
+	// Output:
+	// 1.5GiB
+	// 1000000001B
+	// true
+}
+
+func ExampleByteRate_MarshalText() {
+	for _, s := range []string{"1TB/month", "-5MB/s", "3kB/1h30m"} {
+		rate, _ := bunit.ParseByteRate(s)
+		txt, _ := rate.MarshalText()
+
+		var back bunit.ByteRate
+		back.UnmarshalText(txt)
+		fmt.Println(string(txt), back.Cmp(*rate) == 0)
+	}
+
+	// An amount which prints as a rounded fraction is given in full
+	rate := bunit.NewByteRate(16063712, 917*time.Second)
+	txt, _ := rate.MarshalText()
+	var back bunit.ByteRate
+	back.UnmarshalText(txt)
+	fmt.Printf("%v %s %v\n", rate, txt, back.Cmp(*rate) == 0)
+	// Output:
+	// 1TB/mo true
+	// -5MB/s true
+	// 3kB/90min true
+	// 17.51767939kB/s 16063712B/917s true
+}
+
+func ExampleBytes_UnmarshalJSON() {
+	var conf struct {
+		MaxUpload bunit.Bytes `json:"max_upload"`
+		MinUpload bunit.Bytes `json:"min_upload"`
+	}
+	json.Unmarshal([]byte(`{"max_upload": "2GiB", "min_upload": 1024}`), &conf)
+	fmt.Printf("max = %V, min = %V\n", conf.MaxUpload, conf.MinUpload)
+
+	out, _ := json.Marshal(conf)
+	fmt.Println(string(out))
+
+	// Numbers must be whole and keep to the exponent limit of the parser
+	err := json.Unmarshal([]byte(`{"min_upload": 1.9}`), &conf)
+	fmt.Println(errors.Is(err, bunit.ErrRemainder), err)
+	err = json.Unmarshal([]byte(`{"min_upload": 1e999999}`), &conf)
+	fmt.Println(errors.Is(err, bunit.ErrOverflow))
+	// Output:
+	// max = 2GiB, min = 1KiB
+	// {"max_upload":"2GiB","min_upload":"1KiB"}
+	// true binary unit: value "1.9" is not a whole number of bytes
+	// true
+}
+
+func ExampleBytesVar() {
+	fs := flag.NewFlagSet("example", flag.ContinueOnError)
+	var size bunit.Bytes
+	var rate bunit.ByteRate
+	bunit.BytesVar(fs, &size, "buffer-size", *bunit.NewBytes(4096), "size of the buffer")
+	bunit.ByteRateVar(fs, &rate, "limit", *bunit.NewByteRate(1e6, time.Second), "transfer limit")
+
+	fs.Parse([]string{"--buffer-size=64MiB", "--limit=20MB/s"})
+	fmt.Printf("size = %V, limit = %v\n", size, rate)
+	// Output:
+	// size = 64MiB, limit = 20MB/s
+}
+
+func ExampleBytes_Scan() {
+	// Values read from a database may be integers, numeric text of any length
+	// or a human readable string
+	var quota bunit.Bytes
+	for _, src := range []interface{}{int64(1048576), []byte("1208925819614629174706176"), "1.5GiB"} {
+		quota.Scan(src)
+		fmt.Printf("%V\n", quota)
+	}
+
+	v, _ := quota.Value()
+	fmt.Printf("%T %v\n", v, v)
+
+	// Fractions and oversized exponents are refused
+	for _, src := range []interface{}{"1024.7", 1024.7, "1e999999"} {
+		err := quota.Scan(src)
+		fmt.Println(errors.Is(err, bunit.ErrRemainder), errors.Is(err, bunit.ErrOverflow))
+	}
+	// Output:
+	// 1MiB
+	// 1YiB
+	// 1.5GiB
+	// int64 1610612736
+	// true false
+	// true false
+	// false true
+}
+
+func ExampleBytes_TimeAt() {
+	size := bunit.MustParseBytes("3GB")
+	rate, _ := bunit.ParseByteRate("20MB/s")
+
+	fmt.Println("eta =", size.TimeAt(*rate))
+	fmt.Printf("in 1m = %v\n", rate.Over(time.Minute))
+	fmt.Printf("per hour = %v\n", size.Per(time.Hour))
+	// Output:
+	// eta = 2m30s
+	// in 1m = 1.2GB
+	// per hour = 0.8333333333MB/s
+}
+
+func ExampleNewCountingWriter() {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	w := bunit.NewCountingWriterWithClock(io.Discard, func() time.Time { return now })
+	io.Copy(w, strings.NewReader(strings.Repeat("x", 3<<20)))
+	now = now.Add(2 * time.Second)
+	fmt.Printf("copied %V at %V\n", w.Total(), w.Rate())
+	// Output:
+	// copied 3MiB at 1.5MiB/s
+}
+
+func ExampleNewCountingReader() {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := bunit.NewCountingReaderWithClock(strings.NewReader(strings.Repeat("x", 5e6)), func() time.Time { return now })
+	io.CopyN(io.Discard, r, 2e6)
+	now = now.Add(time.Second)
+	fmt.Printf("read %v at %v\n", r.Total(), r.Rate())
+
+	io.Copy(io.Discard, r)
+	now = now.Add(time.Second)
+	fmt.Printf("read %v at %v\n", r.Total(), r.Rate())
+	// Output:
+	// read 2MB at 2MB/s
+	// read 5MB at 2.5MB/s
+}
+
+func ExampleMeter() {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := bunit.NewMeterWithClock(time.Second, func() time.Time { return now })
+
+	// Move 1MB every tick for two seconds
+	for i := 0; i < 20; i++ {
+		m.Mark(1e6)
+		now = now.Add(bunit.MeterTick)
+	}
+	fmt.Printf("instant = %v\n", m.Instant())
+	fmt.Printf("window = %v\n", m.Window())
+	fmt.Printf("1s = %.4v\n", m.Rate1())
+	// Output:
+	// instant = 10MB/s
+	// window = 10MB/s
+	// 1s = 8.647MB/s
+}
+
+// stepClock gives a clock which jumps ahead in place of sleeping
+func stepClock() (now func() time.Time, after func(time.Duration) <-chan time.Time) {
+	t := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return t }
+	after = func(d time.Duration) <-chan time.Time {
+		t = t.Add(d)
+		c := make(chan time.Time, 1)
+		c <- t
+		return c
+	}
+	return
+}
+
+func ExampleLimitReader() {
+	// Allow 10MB/s, in bursts of up to 1MB
+	rate, _ := bunit.ParseByteRate("1MB/100ms")
+	r := bunit.LimitReader(strings.NewReader(strings.Repeat("x", 3e6)), rate)
+	clock, after := stepClock()
+	r.SetClock(clock, after)
+	start := clock()
+
+	// Each read is no larger than the burst
+	n, _ := r.Read(make([]byte, 5e6))
+	fmt.Println("first read =", n)
+
+	m, _ := io.Copy(io.Discard, r)
+	fmt.Printf("copied %d more in %v\n", m, clock().Sub(start))
+	// Output:
+	// first read = 1000000
+	// copied 2000000 more in 200ms
+}
+
+// chunks records the size of each write
+type chunks []int
+
+func (c *chunks) Write(p []byte) (int, error) {
+	*c = append(*c, len(p))
+	return len(p), nil
+}
+
+func ExampleLimitWriter() {
+	// Allow 80Mb/s, written in chunks of 500kB
+	rate, _ := bunit.ParseBitRate("8Mb/100ms")
+	var out chunks
+	w := bunit.LimitWriter(&out, rate)
+	clock, after := stepClock()
+	w.SetClock(clock, after)
+	w.SetBurst(bunit.MustParseBytes("500kB"))
+	start := clock()
+
+	n, err := w.Write(make([]byte, 2e6))
+	fmt.Println(n, err, out, clock().Sub(start))
+
+	// Once the context is done the write stops after the bytes allowed
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out = nil
+	w = bunit.LimitWriterContext(ctx, &out, rate)
+	w.SetClock(clock, after)
+	n, err = w.Write(make([]byte, 2e6))
+	fmt.Println(n, err, out)
+	// Output:
+	// 2000000 <nil> [500000 500000 500000 500000] 150ms
+	// 1000000 context canceled [1000000]
+}
+
+func ExampleNewLimiterWithClock() {
+	// Jump the clock ahead in place of sleeping
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := now
+	clock := func() time.Time { return now }
+	after := func(d time.Duration) <-chan time.Time {
+		now = now.Add(d)
+		c := make(chan time.Time, 1)
+		c <- now
+		return c
+	}
+	ctx := context.Background()
+
+	// Allow 10MB/s in bursts of up to 1MB
+	rate, _ := bunit.ParseByteRate("1MB/100ms")
+	l := bunit.NewLimiterWithClock(rate, bunit.Bytes{}, clock, after)
+	l.WaitN(ctx, 3e6)
+	fmt.Printf("burst = %v, 3MB took %v\n", l.Burst(), now.Sub(start))
+
+	slow, _ := bunit.ParseByteRate("500kB/s")
+	l.SetRate(slow)
+	start = now
+	l.WaitN(ctx, 2e6)
+	fmt.Printf("2MB took %v\n", now.Sub(start))
+
+	// A zero rate waits until the context is done
+	l.SetRate(bunit.NewByteRate(0, time.Second))
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	fmt.Println(l.WaitN(ctx, 1))
+
+	daily, _ := bunit.ParseByteRate("1kB/day")
+	fmt.Println("burst =", bunit.NewLimiterWithClock(daily, bunit.Bytes{}, clock, after).Burst())
+	// Output:
+	// burst = 1MB, 3MB took 200ms
+	// 2MB took 4s
+	// context deadline exceeded
+	// burst = 1B
+}
+
+func ExampleParseKubeQuantity() {
+	for _, s := range []string{"512Mi", "1.5Gi", "2e9", "100k", "1500000"} {
+		val, format, _ := bunit.ParseKubeQuantity(s)
+		fmt.Printf("%s = %V, canonical %s\n", s, val, bunit.FormatKubeQuantity(val, format))
+	}
+	_, _, err := bunit.ParseKubeQuantity("1e999999")
+	fmt.Println(errors.Is(err, bunit.ErrOverflow))
+	// Output:
+	// 512Mi = 0.5GiB, canonical 512Mi
+	// 1.5Gi = 1.5GiB, canonical 1536Mi
+	// 2e9 = 1.862645149GiB, canonical 2e9
+	// 100k = 97.65625KiB, canonical 100k
+	// 1500000 = 1.4305115MiB, canonical 1500k
+	// true
+}
+
+func ExampleParser_ParseBytes() {
+	// Read sizes from tools where KB and MB are powers of 1024
+	p := bunit.Parser{Convention: bunit.ConventionJEDEC}
+	val, _ := p.ParseBytes("512MB")
+
+	fmt.Printf("%%V = %V\n", val)
+	fmt.Printf("%%#V = %#V\n", val)
+	fmt.Printf("%%#M = %#M\n", val)
+	// Output:
+	// %V = 0.5GiB
+	// %#V = 0.5GB
+	// %#M = 512MB
+}
+
+func ExampleParser() {
+	p := bunit.Parser{
+		SingleTerm:  true,
+		AnySpace:    true,
+		IgnoreCase:  true,
+		DefaultUnit: "B",
+		Aliases:     map[string]string{"octets": "B"},
+	}
+	for _, s := range []string{" 4096 ", "4k", "1.5 KIB", "12 octets", "1k200b"} {
+		val, err := p.ParseBytes(s)
+		fmt.Printf("%q = %B %v\n", s, val, err)
+	}
+	// Output:
+	// " 4096 " = 4096B <nil>
+	// "4k" = 4000B <nil>
+	// "1.5 KIB" = 1536B <nil>
+	// "12 octets" = 12B <nil>
+	// "1k200b" = 0B binary unit: more than one term in value "1k200b"
+}
+
+func ExampleParseError() {
+	in := "12.5 GQB"
+	_, err := bunit.ParseBytes(in)
+
+	var perr *bunit.ParseError
+	if errors.As(err, &perr) && errors.Is(err, bunit.ErrUnknownUnit) {
+		fmt.Println(err)
+		fmt.Println(in)
+		fmt.Printf("%*s^ %q\n", perr.Offset, "", perr.Token)
+	}
+
+	// Spaces around a rate do not move the mark
+	p := bunit.Parser{AnySpace: true}
+	in = "  5MB/xyz  "
+	_, err = p.ParseByteRate(in)
+	if errors.As(err, &perr) && errors.Is(err, bunit.ErrBadDuration) {
+		fmt.Printf("%q\n", in)
+		fmt.Printf(" %*s^ %q\n", perr.Offset, "", perr.Token)
+	}
+	// Output:
+	// binary unit: unknown unit "GQ" in value "12.5 GQB"
+	// 12.5 GQB
+	//      ^ "GQ"
+	// "  5MB/xyz  "
+	//        ^ "xyz"
+}
+
+func ExampleParseBytes_exponent() {
+	for _, s := range []string{"1.5e9B", "1e3 KiB", "0x400 B", "1_048_576B"} {
+		val, _ := bunit.ParseBytes(s)
+		fmt.Printf("%s = %d bytes\n", s, val.Int())
+	}
+
+	_, err := bunit.ParseBits("1e99999999B")
+	var pe *bunit.ParseError
+	if errors.As(err, &pe) {
+		fmt.Printf("%v at %d: %s\n", errors.Is(err, bunit.ErrOverflow), pe.Offset, pe.Token)
+	}
+	// Output:
+	// 1.5e9B = 1500000000 bytes
+	// 1e3 KiB = 1024000 bytes
+	// 0x400 B = 1024 bytes
+	// 1_048_576B = 1048576 bytes
+	// true at 1: e99999999
+}
+
+func ExampleLocale() {
+	p := bunit.Parser{Locale: bunit.LocaleFrench}
+	val, _ := p.ParseBytes("1 234,5 Mo")
+	fmt.Printf("%d\n", val.Int())
+
+	fmt.Printf("%.6v\n", bunit.LocaleFrench.Wrap(val))
+	fmt.Printf("%B\n", bunit.LocaleGerman.Wrap(bunit.MustParseBytes("12345B")))
+	fmt.Printf("%s\n", bunit.LocaleFrench.Wrap(val))
+	// Output:
+	// 1234500000
+	// 1,2345Go
+	// 12.345B
+	// 1,2345 gigaoctet
+}
+
+func ExampleNames() {
+	fmt.Printf("%s\n", bunit.NamesEnglish.Wrap(bunit.MustParseBytes("1kB")))
+	fmt.Printf("%S\n", bunit.NamesEnglish.Wrap(bunit.MustParseBytes("1.5KiB")))
+	fmt.Printf("%s\n", bunit.NamesFrench.Wrap(bunit.MustParseBytes("1.5MB")))
+	fmt.Printf("%s\n", bunit.NamesGerman.Wrap(bunit.MustParseBits("3Mb")))
+	// Output:
+	// 1 Kilobyte
+	// 1.5 Kibibytes
+	// 1.5 mégaoctet
+	// 3 Megabit
+}
+
+func ExampleFormatter() {
+	f := bunit.Formatter{Base: 1024, MaxPrefix: bunit.PrefixGiga, Precision: 2, Space: true}
+	fmt.Println(f.Format(bunit.MustParseBytes("1536KiB")))
+	fmt.Println(f.Format(bunit.MustParseBytes("3TiB")))
+
+	f = bunit.Formatter{Significant: 3, Long: true, Space: true}
+	fmt.Println(f.Format(bunit.MustParseBytes("123456789B")))
+
+	f = bunit.Formatter{RateStyle: bunit.RatePer}
+	rate, _ := bunit.ParseByteRate("20MB/s")
+	fmt.Println(string(f.Append([]byte("limit: "), rate)))
+
+	f = bunit.Formatter{MaxPrefix: bunit.PrefixNone, Locale: bunit.LocaleEnglish}
+	fmt.Println(f.Format(*bunit.NewBytes(-123456)))
+
+	// The zero Formatter writes as many digits as needed, whole units are
+	// rounded to nearest unless floor is asked for
+	f = bunit.Formatter{}
+	fmt.Println(f.Format(bunit.MustParseBytes("1.9kB")))
+	f = bunit.Formatter{Base: 1024, Whole: true}
+	fmt.Println(f.Format(bunit.MustParseBytes("2038B")))
+	f.Rounding = bunit.RoundFloor
+	fmt.Println(f.Format(bunit.MustParseBytes("2038B")))
+	// Output:
+	// 1.50 MiB
+	// 3072.00 GiB
+	// 123 Megabytes
+	// limit: 20MBps
+	// -123,456B
+	// 1.9kB
+	// 2KiB
+	// 1KiB
+}
+
+func ExampleBytes_AppendFormat() {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, "size="...)
+	buf = bunit.NewBytes(1536<<20).AppendFormat(buf, 'V')
+	buf = append(buf, " rate="...)
+	buf = bunit.NewByteRate(20e6, time.Second).AppendFormat(buf, 'v')
+	fmt.Println(string(buf))
+	// Output:
+	// size=1.5GiB rate=20MB/s
+}
+
+func ExampleBytes_Sign() {
+	used := bunit.MustParseBytes("1.5GiB")
+	delta := used.Sub(bunit.MustParseBytes("4.5GiB"))
+	fmt.Printf("delta = %V, sign = %d\n", delta, delta.Sign())
+
+	shrink, _ := bunit.ParseBytes("-512MiB")
+	fmt.Printf("total = %V\n", delta.Add(shrink))
+
+	p := bunit.Parser{NoNegative: true}
+	_, err := p.ParseBytes("-1GiB")
+	fmt.Println(errors.Is(err, bunit.ErrNegative))
+
+	// Slices are always read as unsigned big endian magnitudes
+	padded := bunit.NewBytesFromSlice([]byte{0, 4, 0})
+	fmt.Printf("padded = %v, sign = %d\n", padded, padded.Sign())
+	fmt.Println("signed =", bunit.NewBytes(-3), bunit.NewByteRate(-5, time.Second))
+
+	// The magnitude is the unsigned slice, the sign is kept apart
+	fmt.Println("magnitude =", padded.Magnitude(), delta.Magnitude(), delta.Sign())
+	// Output:
+	// delta = -3GiB, sign = -1
+	// total = -3.5GiB
+	// true
+	// padded = 1.024kB, sign = 1
+	// signed = -3B -5B/s
+	// magnitude = [0 4 0] [192 0 0 0] -1
+}
+
+func ExampleByteRate_In() {
+	egress, _ := bunit.ParseByteRate("1TB/month")
+	ingest, _ := bunit.ParseByteRate("100GB/day")
+
+	fmt.Printf("egress = %v\n", egress.In(bunit.Month))
+	fmt.Printf("ingest = %v, %.4v\n", ingest.In(bunit.Day), ingest.In(bunit.Month))
+	fmt.Printf("ingest = %.4v, %.4v\n", ingest, ingest.In(-time.Second))
+	// Output:
+	// egress = 1TB/mo
+	// ingest = 100GB/d, 3.044TB/mo
+	// ingest = 1.157MB/s, 1.157MB/s
+}
+
+func ExampleByteRate_Add() {
+	link1, _ := bunit.ParseByteRate("5MB/100ms")
+	link2, _ := bunit.ParseByteRate("2GB/min")
+	limit, _ := bunit.ParseByteRate("100MB/s")
+
+	total := link1.Add(*link2)
+	fmt.Printf("total = %.4v\n", total)
+	fmt.Printf("headroom = %.4v\n", limit.Sub(total))
+	fmt.Println("over =", total.Cmp(*limit) > 0)
+	fmt.Printf("bits = %v\n", link1.ToBitRate())
+	// Output:
+	// total = 83.33MB/s
+	// headroom = 16.67MB/s
+	// over = false
+	// bits = 0.4Gbps
+}
+
+func ExampleByteRate_Rat() {
+	r, _ := bunit.ParseByteRate("1GB/7ms")
+
+	fmt.Println("exact =", r.Rat())
+	fmt.Printf("rate = %v, %.3v\n", r, r)
+
+	// Fractions of a bit are kept
+	b, _ := bunit.ParseBitRate("1.5b/s")
+	fmt.Println("bits =", b.Rat(), b)
+	// Output:
+	// exact = 1000000000000/7
+	// rate = 142.8571429GB/s, 143GB/s
+	// bits = 3/2 1.5bps
+}
+
+func ExampleByteRate_Amount() {
+	burst, _ := bunit.ParseByteRate("10MB/100ms")
+
+	fmt.Printf("amount = %v, window = %v\n", burst.Amount(), burst.Duration())
+	fmt.Printf("per second = %v\n", burst.PerSecond())
+	fmt.Printf("over 1m = %v\n", burst.Normalize(time.Minute).Amount())
+
+	r := bunit.NewByteRateFromBytes(burst.Amount(), 50*time.Millisecond)
+	fmt.Printf("doubled = %v\n", r)
+	// Output:
+	// amount = 10MB, window = 100ms
+	// per second = 100MB
+	// over 1m = 6GB
+	// doubled = 200MB/s
+}
+
+func ExampleRateFromCounters() {
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// A 32 bit counter wrapping past 4294967295 during a 10 second poll
+	r, _ := bunit.RateFromCounters(*bunit.NewBytes(4294000000), *bunit.NewBytes(33032704),
+		t0, t0.Add(10*time.Second), 32)
+	fmt.Printf("rate = %v, %v\n", r, r.ToBitRate())
+
+	c := bunit.NewCounterRate(64)
+	for i, v := range []int64{1000, 51000, 200, 10200} {
+		r, err := c.Update(*bunit.NewBytes(v), t0.Add(time.Duration(i)*time.Second))
+		fmt.Println(r, err)
+	}
+	_, err := c.Update(*bunit.NewBytes(20000), t0)
+	fmt.Println(err)
+	// Output:
+	// rate = 3.4MB/s, 27.2Mbps
+	// 0B/s binary unit: no previous counter reading
+	// 50kB/s <nil>
+	// 0B/s binary unit: counter reset
+	// 10kB/s <nil>
+	// binary unit: timestamps not increasing
+}
@@ -2,7 +2,6 @@ module github.com/pschou/go-bunit
 
 go 1.18
 
-require (
-	github.com/cymertek/go-big v0.0.0-20221028234842-57aba6a92118 // indirect
-	golang.org/x/sys v0.0.0-20221006211917-84dc82d7e875 // indirect
-)
+require github.com/cymertek/go-big v0.0.0-20221028234842-57aba6a92118
+
+require golang.org/x/sys v0.0.0-20221006211917-84dc82d7e875 // indirect
@@ -2,31 +2,133 @@ package bunit
 
 import (
 	"errors"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/cymertek/go-big"
 )
 
 var errLeadingInt = errors.New("time: bad [0-9]*") // never printed
 
-// leadingBigFloat consumes the leading [0-9.]* from s.
-func leadingBigFloat(s string) (x *big.Float, rem string, err error) {
+// errExponent is given by leadingNumber for an exponent past maxExponent, the
+// remainder then starts at the exponent
+var errExponent = errors.New("binary unit: exponent too large")
+
+// maxExponent bounds the decimal exponent of a number, well past Quetta, so a
+// value like "1e99999999B" is refused rather than expanded
+const maxExponent = 1000
+
+// leadingNumber consumes the leading number from s exactly.  Decimals may
+// have an exponent, like 1.5e9, and Go style '_' digit separators, like
+// 1_000.  Integers starting with 0x are read as hexadecimal, these take
+// hexadecimal digits greedily so a following b or B unit needs a space.
+func leadingNumber(s string) (x *big.Rat, rem string, err error) {
+	if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		i := 2
+		for ; i < len(s) && (isHex(s[i]) || s[i] == '_' && i+1 < len(s) && isHex(s[i+1])); i++ {
+		}
+		n, ok := (&big.Int{}).SetString(s[:i], 0)
+		if !ok {
+			return nil, rem, errLeadingInt
+		}
+		return (&big.Rat{}).SetInt(n), s[i:], nil
+	}
+
 	i := 0
 	var pt bool
-	var num string
+	var num []byte
 	for ; i < len(s); i++ {
 		c := s[i]
-		if (c < '0' || c > '9') && (pt || c != '.') {
+		if c == '_' && len(num) > 0 && i+1 < len(s) && isDigit(s[i+1]) && isDigit(s[i-1]) {
+			continue
+		}
+		if !isDigit(c) && (pt || c != '.') {
 			break
 		}
-		num += string(c)
+		pt = pt || c == '.'
+		num = append(num, c)
 	}
-	if num == "" {
+	if len(num) == 0 || string(num) == "." {
+		return nil, rem, errLeadingInt
+	}
+
+	// An exponent is only taken when digits follow, so "1EB" stays an exabyte
+	if i+1 < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if s[j] == '+' || s[j] == '-' {
+			j++
+		}
+		if j < len(s) && isDigit(s[j]) {
+			exp := 0
+			for ; j < len(s) && isDigit(s[j]); j++ {
+				if exp = exp*10 + int(s[j]-'0'); exp > maxExponent {
+					return nil, s[i:], errExponent
+				}
+			}
+			num = append(num, s[i:j]...)
+			i = j
+		}
+	}
+	x, ok := (&big.Rat{}).SetString(string(num))
+	if !ok {
 		return nil, rem, errLeadingInt
 	}
-	x, _, _ = (&big.Float{}).Parse(num, 10)
 	return x, s[i:], nil
 }
 
+// exponentText gives the exponent at the start of rem, as left by
+// leadingNumber with errExponent, such as "e99999999"
+func exponentText(rem string) string {
+	j := 2
+	for j < len(rem) && isDigit(rem[j]) {
+		j++
+	}
+	return rem[:j]
+}
+
+// exactNumber reads all of s as a plain signed number, as found in JSON and
+// numeric database columns, with the exponent limit of the parser
+func exactNumber(s string) (*big.Rat, error) {
+	t := strings.TrimPrefix(s, "-")
+	x, rem, err := leadingNumber(t)
+	if err == errExponent {
+		return nil, parseError(s, len(s)-len(rem), exponentText(rem), ErrOverflow, "exponent too large in value "+quote(s))
+	}
+	if err != nil || rem != "" {
+		return nil, parseError(s, 0, s, ErrBadNumber, "invalid value "+quote(s))
+	}
+	if len(t) < len(s) {
+		x.Neg(x)
+	}
+	return x, nil
+}
+
+// wholeNumber gives x when it is a whole number of the unit, such as "bytes",
+// otherwise an error wrapping ErrRemainder naming the text s it came from
+func wholeNumber(x *big.Rat, s, unit string) (num, error) {
+	if !x.IsInt() {
+		return num{}, parseError(s, 0, s, ErrRemainder, "value "+quote(s)+" is not a whole number of "+unit)
+	}
+	return bytesInt(x.Num()), nil
+}
+
+// exactUnit gives the exact value of a unit multiplier, the decimal units
+// past 1e22 are not exact as a float64 so the shortest decimal form is used.
+func exactUnit(f float64) *big.Rat {
+	if frac, _ := math.Frexp(f); frac == 0.5 {
+		return (&big.Rat{}).SetFloat64(f)
+	}
+	r, _ := (&big.Rat{}).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isHex(c byte) bool {
+	return isDigit(c) || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
 // leadingInt consumes the leading [0-9]* from s.
 func leadingInt(s string) (x uint64, rem string, err error) {
 	i := 0